@@ -0,0 +1,409 @@
+package scraper
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheEntry is a stored HTTP response together with its freshness information
+type CacheEntry struct {
+	// URL is the final URL of the cached response
+	URL string `json:"url"`
+	// StatusCode is the HTTP status code of the cached response
+	StatusCode int `json:"status_code"`
+	// Header contains the response headers, including validators
+	Header http.Header `json:"header"`
+	// Body is the raw response body
+	Body []byte `json:"body"`
+	// StoredAt is the time the entry was stored or last revalidated
+	StoredAt time.Time `json:"stored_at"`
+	// Expires is the time after which the entry must be revalidated
+	Expires time.Time `json:"expires"`
+	// Vary holds the request headers named by the Vary response header,
+	// the entry only serves requests sending the same values
+	Vary http.Header `json:"vary,omitempty"`
+}
+
+// Fresh reports whether the entry can be served without contacting the server
+func (e *CacheEntry) Fresh(now time.Time) bool {
+	return now.Before(e.Expires)
+}
+
+// matches reports whether the entry was stored for a request with the same
+// values for every header named by Vary
+func (e *CacheEntry) matches(req *Request) bool {
+	for name, values := range e.Vary {
+		if strings.Join(req.Header.Values(name), ", ") != strings.Join(values, ", ") {
+			return false
+		}
+	}
+	return true
+}
+
+// hasValidators reports whether the entry can be revalidated with a conditional request
+func (e *CacheEntry) hasValidators() bool {
+	return e.Header.Get("ETag") != "" || e.Header.Get("Last-Modified") != ""
+}
+
+// response converts the entry into a Response served from cache
+func (e *CacheEntry) response() *Response {
+	return &Response{
+		StatusCode:  e.StatusCode,
+		Header:      e.Header.Clone(),
+		Body:        e.Body,
+		URL:         e.URL,
+		ContentType: parseContentType(e.Header.Get("Content-Type")),
+		FetchedAt:   e.StoredAt,
+		FromCache:   true,
+	}
+}
+
+// Cache stores HTTP responses keyed by request URL
+// Implementations must be safe for concurrent use
+type Cache interface {
+	// Get returns the entry stored under key, if any
+	Get(key string) (*CacheEntry, bool)
+	// Set stores entry under key, replacing any previous entry
+	Set(key string, entry *CacheEntry) error
+	// Delete removes the entry stored under key
+	Delete(key string) error
+}
+
+// cacheExpiry computes when a response stops being fresh
+// ttl overrides the response headers when positive
+// Returns false if the response must not be stored
+func cacheExpiry(header http.Header, now time.Time, ttl time.Duration) (time.Time, bool) {
+	if ttl > 0 {
+		return now.Add(ttl), true
+	}
+
+	directives := parseCacheControl(header.Get("Cache-Control"))
+	if _, ok := directives["no-store"]; ok {
+		return time.Time{}, false
+	}
+
+	validators := header.Get("ETag") != "" || header.Get("Last-Modified") != ""
+
+	if _, ok := directives["no-cache"]; ok {
+		return now, validators
+	}
+
+	if maxAge, ok := directives["max-age"]; ok {
+		if seconds, err := strconv.Atoi(maxAge); err == nil {
+			return now.Add(time.Duration(seconds) * time.Second), seconds > 0 || validators
+		}
+	}
+
+	if expires := header.Get("Expires"); expires != "" {
+		expiresAt, err := http.ParseTime(expires)
+		if err != nil {
+			// Invalid Expires means already expired
+			return now, validators
+		}
+		// Use the server clock when available to avoid skew
+		if date, err := http.ParseTime(header.Get("Date")); err == nil {
+			return now.Add(expiresAt.Sub(date)), true
+		}
+		return expiresAt, true
+	}
+
+	// No freshness information, keep only if it can be revalidated
+	return now, validators
+}
+
+// parseCacheControl splits a Cache-Control header into lower-cased directives
+func parseCacheControl(value string) map[string]string {
+	directives := make(map[string]string)
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, arg, _ := strings.Cut(part, "=")
+		directives[strings.ToLower(strings.TrimSpace(name))] = strings.Trim(strings.TrimSpace(arg), `"`)
+	}
+	return directives
+}
+
+// memoryCache is an in-memory least recently used Cache
+type memoryCache struct {
+	maxEntries int
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type memoryCacheItem struct {
+	key   string
+	entry *CacheEntry
+}
+
+// NewMemoryCache returns an in-memory LRU Cache holding at most maxEntries responses
+// A maxEntries of 0 or less means no limit
+func NewMemoryCache(maxEntries int) Cache {
+	return &memoryCache{
+		maxEntries: maxEntries,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// Get returns the entry stored under key and marks it as recently used
+func (c *memoryCache) Get(key string) (*CacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*memoryCacheItem).entry, true
+}
+
+// Set stores entry under key, evicting the least recently used entry if full
+func (c *memoryCache) Set(key string, entry *CacheEntry) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		elem.Value.(*memoryCacheItem).entry = entry
+		c.order.MoveToFront(elem)
+		return nil
+	}
+
+	c.entries[key] = c.order.PushFront(&memoryCacheItem{key: key, entry: entry})
+
+	if c.maxEntries > 0 && c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*memoryCacheItem).key)
+	}
+
+	return nil
+}
+
+// Delete removes the entry stored under key
+func (c *memoryCache) Delete(key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+	}
+	return nil
+}
+
+// diskCache is a Cache storing one JSON file per entry in a directory
+type diskCache struct {
+	dir string
+}
+
+// NewDiskCache returns a Cache that stores responses as files in dir
+// The directory is created if it does not exist
+func NewDiskCache(dir string) (Cache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &diskCache{dir: dir}, nil
+}
+
+// path returns the file used to store key
+func (c *diskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// Get reads the entry stored under key, unreadable files are treated as misses
+func (c *diskCache) Get(key string) (*CacheEntry, bool) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+
+	var entry CacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, false
+	}
+	return &entry, true
+}
+
+// Set writes entry to disk, replacing the previous file atomically
+func (c *diskCache) Set(key string, entry *CacheEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(c.dir, "entry-*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path(key))
+}
+
+// Delete removes the file stored under key
+func (c *diskCache) Delete(key string) error {
+	err := os.Remove(c.path(key))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// cacheKey returns the cache key for req, empty if req is not cacheable
+// Requests with different headers are cached apart, except for User-Agent
+// which only matters when the response varies on it. Requests carrying
+// cookies or credentials are not cached at all
+func (s *Scraper) cacheKey(req *Request) string {
+	if s.options.Cache == nil || req.method() != http.MethodGet {
+		return ""
+	}
+	if req.Header.Get("Cookie") != "" || req.Header.Get("Authorization") != "" {
+		return ""
+	}
+	if u, err := url.Parse(req.URL); err != nil || (s.session != nil && len(s.session.Cookies(u)) > 0) {
+		return ""
+	}
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		if name = http.CanonicalHeaderKey(name); name != "User-Agent" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var key strings.Builder
+	key.WriteString(req.URL)
+	for _, name := range names {
+		key.WriteString("\n" + name + ": " + strings.Join(req.Header.Values(name), ", "))
+	}
+	return key.String()
+}
+
+// varyHeaders returns the values req sent for the headers named by the Vary
+// header of a response, false if the response varies on everything
+func varyHeaders(header http.Header, req *Request) (http.Header, bool) {
+	var vary http.Header
+	for _, value := range header.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.TrimSpace(name)
+			switch name {
+			case "":
+				continue
+			case "*":
+				return nil, false
+			}
+			if vary == nil {
+				vary = http.Header{}
+			}
+			vary[http.CanonicalHeaderKey(name)] = req.Header.Values(name)
+		}
+	}
+	return vary, true
+}
+
+// conditionalHeaders builds revalidation headers from the validators of entry
+func conditionalHeaders(entry *CacheEntry) http.Header {
+	header := http.Header{}
+	if etag := entry.Header.Get("ETag"); etag != "" {
+		header.Set("If-None-Match", etag)
+	}
+	if lastModified := entry.Header.Get("Last-Modified"); lastModified != "" {
+		header.Set("If-Modified-Since", lastModified)
+	}
+	return header
+}
+
+// cacheLookup returns the cached entry stored under key for req, if any
+func (s *Scraper) cacheLookup(key string, req *Request) *CacheEntry {
+	if key == "" {
+		return nil
+	}
+
+	entry, ok := s.options.Cache.Get(key)
+	if !ok || !entry.matches(req) {
+		return nil
+	}
+	// Stale entries without validators are useless
+	if !entry.Fresh(time.Now()) && !entry.hasValidators() {
+		return nil
+	}
+	return entry
+}
+
+// cacheStore records resp for req under key and returns the response to hand to the caller
+// A 304 Not Modified refreshes entry and returns its cached body
+func (s *Scraper) cacheStore(key string, req *Request, entry *CacheEntry, resp *Response) *Response {
+	if key == "" {
+		return resp
+	}
+
+	now := time.Now()
+
+	if resp.StatusCode == http.StatusNotModified && entry != nil {
+		// Merge updated headers from the 304 into a copy of the stored entry,
+		// the original may be shared with concurrent readers
+		refreshed := *entry
+		refreshed.Header = entry.Header.Clone()
+		for name, values := range resp.Header {
+			refreshed.Header[name] = values
+		}
+		entry = &refreshed
+		expires, ok := cacheExpiry(entry.Header, now, s.options.CacheTTL)
+		entry.StoredAt = now
+		entry.Expires = expires
+		if ok {
+			// Caching is best-effort, a failed write only costs a future request
+			_ = s.options.Cache.Set(key, entry)
+		} else {
+			_ = s.options.Cache.Delete(key)
+		}
+
+		cached := entry.response()
+		cached.RequestURL = resp.RequestURL
+		cached.Duration = resp.Duration
+		cached.Attempts = resp.Attempts
+		return cached
+	}
+
+	expires, ok := cacheExpiry(resp.Header, now, s.options.CacheTTL)
+	vary, cacheable := varyHeaders(resp.Header, req)
+	if !ok || !cacheable {
+		_ = s.options.Cache.Delete(key)
+		return resp
+	}
+
+	_ = s.options.Cache.Set(key, &CacheEntry{
+		URL:        resp.URL,
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header.Clone(),
+		Body:       resp.Body,
+		StoredAt:   now,
+		Expires:    expires,
+		Vary:       vary,
+	})
+	return resp
+}
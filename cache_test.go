@@ -0,0 +1,328 @@
+package scraper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestCache_FreshResponse verifies fresh responses are served without a request
+func TestCache_FreshResponse(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Cache-Control", "max-age=60")
+		_, _ = w.Write([]byte("<h1>Cached</h1>"))
+	}))
+	defer server.Close()
+
+	s := New(Options{MaxRetries: 1, Cache: NewMemoryCache(10)})
+
+	for i := 0; i < 3; i++ {
+		resp, err := s.Fetch(server.URL)
+		if err != nil {
+			t.Fatalf("Fetch() error = %v", err)
+		}
+		if resp.HTML() != "<h1>Cached</h1>" {
+			t.Errorf("Fetch() body = %q", resp.HTML())
+		}
+		if resp.FromCache != (i > 0) {
+			t.Errorf("Fetch #%d FromCache = %v", i+1, resp.FromCache)
+		}
+	}
+
+	if hits.Load() != 1 {
+		t.Errorf("Expected 1 request to the server, got %d", hits.Load())
+	}
+}
+
+// TestCache_Revalidation verifies stale entries are revalidated with validators
+func TestCache_Revalidation(t *testing.T) {
+	var full, conditional atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` &&
+			r.Header.Get("If-Modified-Since") == "Mon, 01 Jan 2024 00:00:00 GMT" {
+			conditional.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full.Add(1)
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Last-Modified", "Mon, 01 Jan 2024 00:00:00 GMT")
+		_, _ = w.Write([]byte("body-v1"))
+	}))
+	defer server.Close()
+
+	s := New(Options{MaxRetries: 1, Cache: NewMemoryCache(10)})
+
+	for i := 0; i < 3; i++ {
+		html, err := s.ScrapeHTML(server.URL)
+		if err != nil {
+			t.Fatalf("ScrapeHTML() error = %v", err)
+		}
+		if html != "body-v1" {
+			t.Errorf("ScrapeHTML() = %q, want cached body", html)
+		}
+	}
+
+	if full.Load() != 1 || conditional.Load() != 2 {
+		t.Errorf("Expected 1 full and 2 conditional requests, got %d and %d", full.Load(), conditional.Load())
+	}
+}
+
+// TestCache_NoStore verifies no-store responses are never cached
+func TestCache_NoStore(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte("secret"))
+	}))
+	defer server.Close()
+
+	s := New(Options{MaxRetries: 1, Cache: NewMemoryCache(10)})
+	for i := 0; i < 2; i++ {
+		if _, err := s.ScrapeHTML(server.URL); err != nil {
+			t.Fatalf("ScrapeHTML() error = %v", err)
+		}
+	}
+
+	if hits.Load() != 2 {
+		t.Errorf("Expected 2 requests, got %d", hits.Load())
+	}
+}
+
+// TestCache_Variants verifies responses are cached per request headers and
+// Vary, and requests with cookies bypass the cache
+func TestCache_Variants(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Cache-Control", "max-age=60")
+		switch r.URL.Path {
+		case "/vary":
+			w.Header().Set("Vary", "Accept-Encoding, User-Agent")
+			_, _ = w.Write([]byte(r.UserAgent()))
+		case "/vary-all":
+			w.Header().Set("Vary", "*")
+			_, _ = w.Write([]byte("varies"))
+		case "/login":
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "1"})
+		default:
+			_, _ = w.Write([]byte(r.Header.Get("Accept-Language") + r.Header.Get("Cookie")))
+		}
+	}))
+	defer server.Close()
+
+	cache := NewMemoryCache(10)
+	s := New(Options{MaxRetries: 1, Cache: cache})
+
+	fetch := func(t *testing.T, s *Scraper, req *Request) *Response {
+		t.Helper()
+		resp, err := s.FetchRequest(context.Background(), req)
+		if err != nil {
+			t.Fatalf("FetchRequest() error = %v", err)
+		}
+		return resp
+	}
+	language := func(lang string) *Request {
+		return &Request{URL: server.URL + "/page", Header: http.Header{"Accept-Language": {lang}}}
+	}
+
+	t.Run("Request headers", func(t *testing.T) {
+		for _, lang := range []string{"en", "de", "en", "de"} {
+			if resp := fetch(t, s, language(lang)); resp.HTML() != lang {
+				t.Errorf("Accept-Language %s body = %q", lang, resp.HTML())
+			}
+		}
+		if hits.Load() != 2 {
+			t.Errorf("Expected 2 requests, one per language, got %d", hits.Load())
+		}
+	})
+
+	t.Run("Vary", func(t *testing.T) {
+		hits.Store(0)
+		// The user agent is left out of the key, the response varies on it
+		other := New(Options{MaxRetries: 1, Cache: cache, UserAgent: "other-agent"})
+		for _, sc := range []*Scraper{s, other, other} {
+			resp := fetch(t, sc, &Request{URL: server.URL + "/vary"})
+			if resp.HTML() != sc.options.UserAgent {
+				t.Errorf("Vary: User-Agent body = %q, want %q", resp.HTML(), sc.options.UserAgent)
+			}
+		}
+		for i := 0; i < 2; i++ {
+			fetch(t, s, &Request{URL: server.URL + "/vary-all"})
+		}
+		if hits.Load() != 4 {
+			t.Errorf("Expected 4 requests, got %d", hits.Load())
+		}
+	})
+
+	t.Run("Cookies", func(t *testing.T) {
+		hits.Store(0)
+		fetch(t, s, &Request{URL: server.URL + "/other", Header: http.Header{"Cookie": {"a=1"}}})
+		fetch(t, s, &Request{URL: server.URL + "/other", Header: http.Header{"Cookie": {"a=1"}}})
+		fetch(t, s, &Request{URL: server.URL + "/login"})
+		if resp := fetch(t, s, language("en")); resp.HTML() != "ensession=1" || resp.FromCache {
+			t.Errorf("Body with a session cookie = %q, FromCache = %v", resp.HTML(), resp.FromCache)
+		}
+		if hits.Load() != 4 {
+			t.Errorf("Expected 4 requests, got %d", hits.Load())
+		}
+	})
+}
+
+// TestCache_ForcedTTL verifies CacheTTL overrides response headers
+func TestCache_ForcedTTL(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Cache-Control", "no-store")
+		_, _ = w.Write([]byte("page"))
+	}))
+	defer server.Close()
+
+	s := New(Options{MaxRetries: 1, Cache: NewMemoryCache(10), CacheTTL: time.Minute})
+	for i := 0; i < 3; i++ {
+		if _, err := s.ScrapeHTML(server.URL); err != nil {
+			t.Fatalf("ScrapeHTML() error = %v", err)
+		}
+	}
+
+	if hits.Load() != 1 {
+		t.Errorf("Expected 1 request, got %d", hits.Load())
+	}
+}
+
+// TestCache_Paginated verifies both pagination modes use the cache
+func TestCache_Paginated(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Cache-Control", "max-age=60")
+		_, _ = w.Write([]byte(`<div class="item">Item</div><span class="total-pages">3</span>`))
+	}))
+	defer server.Close()
+
+	s := New(Options{MaxRetries: 1, Cache: NewMemoryCache(10)})
+	configs := []PaginationConfig{
+		{LastPageSelector: "span.total-pages", NextPageURLPattern: "/page::page::"},
+		{LastPageSelector: "span.total-pages", NextPageURLPattern: "/page::page::"},
+		{NextPageSelector: "a.next[href]"},
+	}
+
+	for _, config := range configs {
+		resultsChan, err := s.ScrapePaginated(server.URL+"/", "div.item", config)
+		if err != nil {
+			t.Fatalf("ScrapePaginated() error = %v", err)
+		}
+		for result := range resultsChan {
+			if result.Err != nil {
+				t.Errorf("Received error from channel: %v", result.Err)
+			}
+		}
+	}
+
+	if hits.Load() != 3 {
+		t.Errorf("Expected 3 requests to the server, got %d", hits.Load())
+	}
+}
+
+// TestMemoryCache_LRU verifies least recently used entries are evicted first
+func TestMemoryCache_LRU(t *testing.T) {
+	c := NewMemoryCache(2)
+	_ = c.Set("a", &CacheEntry{URL: "a"})
+	_ = c.Set("b", &CacheEntry{URL: "b"})
+	c.Get("a")
+	_ = c.Set("c", &CacheEntry{URL: "c"})
+
+	if _, ok := c.Get("b"); ok {
+		t.Error("Expected 'b' to be evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := c.Get(key); !ok {
+			t.Errorf("Expected %q to be cached", key)
+		}
+	}
+
+	_ = c.Delete("a")
+	if _, ok := c.Get("a"); ok {
+		t.Error("Expected 'a' to be deleted")
+	}
+}
+
+// TestDiskCache verifies entries survive across cache instances
+func TestDiskCache(t *testing.T) {
+	dir := t.TempDir()
+	c, err := NewDiskCache(dir)
+	if err != nil {
+		t.Fatalf("NewDiskCache() error = %v", err)
+	}
+
+	expires := time.Now().Add(time.Hour).Round(0)
+	entry := &CacheEntry{
+		URL:        "https://example.com/",
+		StatusCode: 200,
+		Header:     http.Header{"Etag": []string{`"v1"`}},
+		Body:       []byte("hello"),
+		Expires:    expires,
+	}
+	if err := c.Set("https://example.com/", entry); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	reopened, _ := NewDiskCache(dir)
+	got, ok := reopened.Get("https://example.com/")
+	if !ok {
+		t.Fatal("Expected entry to be read back from disk")
+	}
+	if string(got.Body) != "hello" || got.Header.Get("ETag") != `"v1"` || !got.Expires.Equal(expires) {
+		t.Errorf("Unexpected entry read back: %+v", got)
+	}
+
+	if err := reopened.Delete("https://example.com/"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, ok := c.Get("https://example.com/"); ok {
+		t.Error("Expected entry to be deleted")
+	}
+}
+
+// TestCacheExpiry verifies freshness is derived from response headers
+func TestCacheExpiry(t *testing.T) {
+	now := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+	date := now.Format(http.TimeFormat)
+
+	tests := []struct {
+		name     string
+		header   http.Header
+		expected time.Duration
+		storable bool
+	}{
+		{"max-age", http.Header{"Cache-Control": {"public, max-age=" + strconv.Itoa(300)}}, 5 * time.Minute, true},
+		{"no-store", http.Header{"Cache-Control": {"no-store"}}, 0, false},
+		{"no-cache with ETag", http.Header{"Cache-Control": {"no-cache"}, "Etag": {`"x"`}}, 0, true},
+		{"no-cache without validators", http.Header{"Cache-Control": {"no-cache"}}, 0, false},
+		{"Expires", http.Header{"Date": {date}, "Expires": {now.Add(time.Hour).Format(http.TimeFormat)}}, time.Hour, true},
+		{"Nothing", http.Header{}, 0, false},
+		{"Last-Modified only", http.Header{"Last-Modified": {date}}, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expires, ok := cacheExpiry(tt.header, now, 0)
+			if ok != tt.storable {
+				t.Errorf("cacheExpiry() storable = %v, want %v", ok, tt.storable)
+			}
+			if ok && expires.Sub(now) != tt.expected {
+				t.Errorf("cacheExpiry() freshness = %v, want %v", expires.Sub(now), tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,341 @@
+package scraper
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"unicode/utf8"
+)
+
+// ErrInteractionNotFound is returned in replay mode when no recorded
+// interaction matches a request
+var ErrInteractionNotFound = errors.New("no recorded interaction matches request")
+
+// RecordMode selects whether a Recorder talks to the network
+type RecordMode int
+
+const (
+	// ModeReplay serves responses from the cassette and never touches the network
+	ModeReplay RecordMode = iota
+	// ModeRecord performs real requests and saves every interaction to the cassette
+	ModeRecord
+)
+
+// RecordedRequest is the request half of a recorded interaction
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   []byte      `json:"-"`
+}
+
+// RecordedResponse is the response half of a recorded interaction
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	URL        string      `json:"url"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       []byte      `json:"-"`
+}
+
+// Interaction is a single request/response pair stored in a cassette
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// cassetteBody stores bodies as readable text when possible and base64 otherwise
+type cassetteBody struct {
+	Body       *string `json:"body,omitempty"`
+	BodyBase64 *string `json:"body_base64,omitempty"`
+}
+
+func encodeBody(body []byte) cassetteBody {
+	if body == nil {
+		return cassetteBody{}
+	}
+	if utf8.Valid(body) {
+		text := string(body)
+		return cassetteBody{Body: &text}
+	}
+	encoded := base64.StdEncoding.EncodeToString(body)
+	return cassetteBody{BodyBase64: &encoded}
+}
+
+func (b cassetteBody) decode() ([]byte, error) {
+	switch {
+	case b.Body != nil:
+		return []byte(*b.Body), nil
+	case b.BodyBase64 != nil:
+		return base64.StdEncoding.DecodeString(*b.BodyBase64)
+	default:
+		return nil, nil
+	}
+}
+
+type recordedRequestJSON struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	cassetteBody
+}
+
+// MarshalJSON stores the body as text or base64
+func (r RecordedRequest) MarshalJSON() ([]byte, error) {
+	return json.Marshal(recordedRequestJSON{r.Method, r.URL, r.Header, encodeBody(r.Body)})
+}
+
+// UnmarshalJSON restores a request stored by MarshalJSON
+func (r *RecordedRequest) UnmarshalJSON(data []byte) error {
+	var raw recordedRequestJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	body, err := raw.decode()
+	if err != nil {
+		return err
+	}
+	*r = RecordedRequest{Method: raw.Method, URL: raw.URL, Header: raw.Header, Body: body}
+	return nil
+}
+
+type recordedResponseJSON struct {
+	StatusCode int         `json:"status_code"`
+	URL        string      `json:"url"`
+	Header     http.Header `json:"header,omitempty"`
+	cassetteBody
+}
+
+// MarshalJSON stores the body as text or base64
+func (r RecordedResponse) MarshalJSON() ([]byte, error) {
+	return json.Marshal(recordedResponseJSON{r.StatusCode, r.URL, r.Header, encodeBody(r.Body)})
+}
+
+// UnmarshalJSON restores a response stored by MarshalJSON
+func (r *RecordedResponse) UnmarshalJSON(data []byte) error {
+	var raw recordedResponseJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	body, err := raw.decode()
+	if err != nil {
+		return err
+	}
+	*r = RecordedResponse{StatusCode: raw.StatusCode, URL: raw.URL, Header: raw.Header, Body: body}
+	return nil
+}
+
+// Matcher reports whether a recorded request can answer req
+type Matcher func(req *Request, recorded *RecordedRequest) bool
+
+// StrictMatcher requires the method, URL, headers and body to be identical
+// User-Agent is ignored so cassettes replay under any Options.UserAgent
+func StrictMatcher(req *Request, recorded *RecordedRequest) bool {
+	return req.method() == recorded.Method &&
+		req.URL == recorded.URL &&
+		headersEqual(withoutUserAgent(req.Header), withoutUserAgent(recorded.Header)) &&
+		bytes.Equal(req.Body, recorded.Body)
+}
+
+// withoutUserAgent returns header without its User-Agent
+func withoutUserAgent(header http.Header) http.Header {
+	if header.Get("User-Agent") == "" {
+		return header
+	}
+	header = header.Clone()
+	header.Del("User-Agent")
+	return header
+}
+
+// LenientMatcher requires the same method and URL, ignoring query parameter
+// order, headers and body
+func LenientMatcher(req *Request, recorded *RecordedRequest) bool {
+	return req.method() == recorded.Method && sameURLIgnoringQueryOrder(req.URL, recorded.URL)
+}
+
+func headersEqual(a, b http.Header) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key, values := range a {
+		other := b.Values(key)
+		if len(values) != len(other) {
+			return false
+		}
+		for i := range values {
+			if values[i] != other[i] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func sameURLIgnoringQueryOrder(a, b string) bool {
+	ua, errA := url.Parse(a)
+	ub, errB := url.Parse(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	// Query.Encode sorts by key, giving a canonical form
+	ua.RawQuery = ua.Query().Encode()
+	ub.RawQuery = ub.Query().Encode()
+	return ua.String() == ub.String()
+}
+
+// RecorderOptions configures a Recorder
+type RecorderOptions struct {
+	// Mode selects recording or replaying, defaults to ModeReplay
+	Mode RecordMode
+	// Fetcher performs the real requests in record mode, defaults to the colly
+	// based fetcher. The Scraper sets the User-Agent header of every request
+	// and checks Options.AllowedDomains, including after redirects
+	Fetcher Fetcher
+	// Matcher selects the recorded interaction answering a request in replay mode
+	// Defaults to StrictMatcher
+	Matcher Matcher
+}
+
+// Recorder is a Fetcher that records interactions to a cassette file or replays them
+// Use it as Options.Fetcher to run scrapes fully offline against committed fixtures
+type Recorder struct {
+	path    string
+	options RecorderOptions
+
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// NewRecorder creates a Recorder backed by the cassette at path
+// In replay mode the cassette must exist, in record mode it is replaced
+func NewRecorder(path string, opts RecorderOptions) (*Recorder, error) {
+	if opts.Fetcher == nil {
+		opts.Fetcher = NewCollyFetcher(Options{})
+	}
+	if opts.Matcher == nil {
+		opts.Matcher = StrictMatcher
+	}
+
+	r := &Recorder{path: path, options: opts}
+
+	if opts.Mode == ModeReplay {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read cassette %s: %w", path, err)
+		}
+		if err := json.Unmarshal(data, &r.interactions); err != nil {
+			return nil, fmt.Errorf("failed to parse cassette %s: %w", path, err)
+		}
+		r.used = make([]bool, len(r.interactions))
+	}
+
+	return r, nil
+}
+
+// Interactions returns a copy of the interactions recorded or loaded so far
+func (r *Recorder) Interactions() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Interaction(nil), r.interactions...)
+}
+
+// Fetch records or replays req depending on the mode
+func (r *Recorder) Fetch(ctx context.Context, req *Request) (*Response, error) {
+	if r.options.Mode == ModeRecord {
+		return r.record(ctx, req)
+	}
+	return r.replay(ctx, req)
+}
+
+// record performs req and appends the interaction to the cassette
+func (r *Recorder) record(ctx context.Context, req *Request) (*Response, error) {
+	resp, err := r.options.Fetcher.Fetch(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	interaction := Interaction{
+		Request: RecordedRequest{
+			Method: req.method(),
+			URL:    req.URL,
+			Header: req.Header.Clone(),
+			Body:   req.Body,
+		},
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			URL:        resp.URL,
+			Header:     resp.Header.Clone(),
+			Body:       resp.Body,
+		},
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.interactions = append(r.interactions, interaction)
+	if err := r.save(); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// replay answers req from the cassette
+// Matching interactions are served in recorded order, the last one is reused once exhausted
+func (r *Recorder) replay(ctx context.Context, req *Request) (*Response, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	last := -1
+	for i := range r.interactions {
+		if !r.options.Matcher(req, &r.interactions[i].Request) {
+			continue
+		}
+		last = i
+		if !r.used[i] {
+			r.used[i] = true
+			return recordedResponse(&r.interactions[i].Response), nil
+		}
+	}
+
+	if last >= 0 {
+		return recordedResponse(&r.interactions[last].Response), nil
+	}
+
+	return nil, fmt.Errorf("%w: %s %s", ErrInteractionNotFound, req.method(), req.URL)
+}
+
+func recordedResponse(recorded *RecordedResponse) *Response {
+	return &Response{
+		StatusCode: recorded.StatusCode,
+		Header:     recorded.Header.Clone(),
+		Body:       recorded.Body,
+		URL:        recorded.URL,
+	}
+}
+
+// save writes all interactions to the cassette file, caller must hold r.mu
+func (r *Recorder) save() error {
+	data, err := json.MarshalIndent(r.interactions, "", "  ")
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(r.path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(r.path, data, 0o644)
+}
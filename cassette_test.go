@@ -0,0 +1,229 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+// TestRecorder_RecordAndReplay verifies a paginated scrape can be replayed offline
+func TestRecorder_RecordAndReplay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			_, _ = w.Write([]byte(`<div class="item">Page 1</div><a class="next" href="/page2">Next</a>`))
+		case "/page2":
+			_, _ = w.Write([]byte(`<div class="item">Page 2</div>`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	cassette := filepath.Join(t.TempDir(), "fixtures", "pages.json")
+	config := PaginationConfig{NextPageSelector: "a.next[href]"}
+
+	collect := func(s *Scraper) []string {
+		resultsChan, err := s.ScrapePaginated(server.URL+"/", "div.item", config)
+		if err != nil {
+			t.Fatalf("ScrapePaginated() error = %v", err)
+		}
+		var results []string
+		for result := range resultsChan {
+			if result.Err != nil {
+				t.Errorf("Received error from channel: %v", result.Err)
+				continue
+			}
+			results = append(results, result.Data)
+		}
+		return results
+	}
+
+	recorder, err := NewRecorder(cassette, RecorderOptions{Mode: ModeRecord})
+	if err != nil {
+		t.Fatalf("NewRecorder() error = %v", err)
+	}
+	recorded := collect(New(Options{MaxRetries: 1, Fetcher: recorder}))
+	if len(recorder.Interactions()) != 2 {
+		t.Fatalf("Expected 2 recorded interactions, got %d", len(recorder.Interactions()))
+	}
+
+	// The server is gone, replay must not touch the network
+	server.Close()
+
+	player, err := NewRecorder(cassette, RecorderOptions{Mode: ModeReplay})
+	if err != nil {
+		t.Fatalf("NewRecorder() error = %v", err)
+	}
+	replayed := collect(New(Options{MaxRetries: 1, Fetcher: player}))
+
+	if len(replayed) != 2 || len(recorded) != 2 {
+		t.Fatalf("Expected 2 results in both runs, got %d and %d", len(recorded), len(replayed))
+	}
+	for i := range recorded {
+		if recorded[i] != replayed[i] {
+			t.Errorf("Result %d differs: recorded %q, replayed %q", i, recorded[i], replayed[i])
+		}
+	}
+}
+
+// TestRecorder_ReplayOrder verifies repeated requests are answered in recorded order
+func TestRecorder_ReplayOrder(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	cassette := filepath.Join(t.TempDir(), "retry.json")
+	recorder, _ := NewRecorder(cassette, RecorderOptions{Mode: ModeRecord})
+	s := New(Options{MaxRetries: 2, Fetcher: recorder, RetryPolicy: fastRetryPolicy()})
+	if _, err := s.ScrapeHTML(server.URL); err != nil {
+		t.Fatalf("ScrapeHTML() error = %v", err)
+	}
+
+	player, _ := NewRecorder(cassette, RecorderOptions{Mode: ModeReplay})
+	ctx := context.Background()
+	for i, want := range []int{http.StatusServiceUnavailable, http.StatusOK, http.StatusOK} {
+		resp, err := player.Fetch(ctx, &Request{Method: http.MethodGet, URL: server.URL})
+		if err != nil {
+			t.Fatalf("Fetch #%d error = %v", i+1, err)
+		}
+		if resp.StatusCode != want {
+			t.Errorf("Fetch #%d status = %d, want %d", i+1, resp.StatusCode, want)
+		}
+	}
+}
+
+// TestRecorder_ScraperOptions verifies recorded requests carry the user agent of
+// the Scraper, redirects honour AllowedDomains and cassettes replay under any user agent
+func TestRecorder_ScraperOptions(t *testing.T) {
+	var userAgents []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgents = append(userAgents, r.UserAgent())
+		if r.URL.Path == "/away" {
+			_, port, _ := net.SplitHostPort(r.Host)
+			http.Redirect(w, r, "http://localhost:"+port+"/", http.StatusFound)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	cassette := filepath.Join(t.TempDir(), "agent.json")
+	recorder, _ := NewRecorder(cassette, RecorderOptions{Mode: ModeRecord})
+	s := New(Options{MaxRetries: 1, Fetcher: recorder, UserAgent: "MyBot/1.0", AllowedDomains: []string{"127.0.0.1"}})
+
+	if _, err := s.ScrapeHTML(server.URL); err != nil {
+		t.Fatalf("ScrapeHTML() error = %v", err)
+	}
+	if len(userAgents) != 1 || userAgents[0] != "MyBot/1.0" {
+		t.Errorf("Server saw user agents %v, want [MyBot/1.0]", userAgents)
+	}
+	if _, err := s.ScrapeHTML(server.URL + "/away"); !errors.Is(err, ErrDomainNotAllowed) {
+		t.Errorf("ScrapeHTML() of a redirect off the allowed domains error = %v, want ErrDomainNotAllowed", err)
+	}
+
+	player, _ := NewRecorder(cassette, RecorderOptions{Mode: ModeReplay})
+	if html, err := New(Options{MaxRetries: 1, Fetcher: player}).ScrapeHTML(server.URL); err != nil || html != "ok" {
+		t.Errorf("Replay with another user agent = %q, %v", html, err)
+	}
+}
+
+// TestRecorder_Matchers verifies strict and lenient matching
+func TestRecorder_Matchers(t *testing.T) {
+	recorded := &RecordedRequest{
+		Method: http.MethodPost,
+		URL:    "https://example.com/search?a=1&b=2",
+		Header: http.Header{"Content-Type": {"application/json"}},
+		Body:   []byte(`{"q":"shoes"}`),
+	}
+
+	tests := []struct {
+		name    string
+		req     *Request
+		strict  bool
+		lenient bool
+	}{
+		{
+			"Identical",
+			&Request{Method: http.MethodPost, URL: recorded.URL, Header: recorded.Header.Clone(), Body: recorded.Body},
+			true, true,
+		},
+		{
+			"Query order",
+			&Request{Method: http.MethodPost, URL: "https://example.com/search?b=2&a=1", Header: recorded.Header.Clone(), Body: recorded.Body},
+			false, true,
+		},
+		{
+			"Different body",
+			&Request{Method: http.MethodPost, URL: recorded.URL, Header: recorded.Header.Clone(), Body: []byte(`{}`)},
+			false, true,
+		},
+		{
+			"Different header",
+			&Request{Method: http.MethodPost, URL: recorded.URL, Body: recorded.Body},
+			false, true,
+		},
+		{
+			"Different method",
+			&Request{Method: http.MethodGet, URL: recorded.URL},
+			false, false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StrictMatcher(tt.req, recorded); got != tt.strict {
+				t.Errorf("StrictMatcher() = %v, want %v", got, tt.strict)
+			}
+			if got := LenientMatcher(tt.req, recorded); got != tt.lenient {
+				t.Errorf("LenientMatcher() = %v, want %v", got, tt.lenient)
+			}
+		})
+	}
+}
+
+// TestRecorder_ReplayMiss verifies unmatched requests fail without network access
+func TestRecorder_ReplayMiss(t *testing.T) {
+	cassette := filepath.Join(t.TempDir(), "binary.json")
+	recorder, _ := NewRecorder(cassette, RecorderOptions{
+		Mode: ModeRecord,
+		Fetcher: FetcherFunc(func(ctx context.Context, req *Request) (*Response, error) {
+			return &Response{StatusCode: http.StatusOK, Body: []byte{0x1f, 0x8b, 0xff}, URL: req.URL}, nil
+		}),
+	})
+	if _, err := recorder.Fetch(context.Background(), &Request{URL: "https://example.com/a.gz"}); err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	player, err := NewRecorder(cassette, RecorderOptions{Mode: ModeReplay, Matcher: LenientMatcher})
+	if err != nil {
+		t.Fatalf("NewRecorder() error = %v", err)
+	}
+
+	resp, err := player.Fetch(context.Background(), &Request{URL: "https://example.com/a.gz"})
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if string(resp.Body) != string([]byte{0x1f, 0x8b, 0xff}) {
+		t.Errorf("Expected binary body to round-trip, got %v", resp.Body)
+	}
+
+	_, err = player.Fetch(context.Background(), &Request{URL: "https://example.com/other"})
+	if !errors.Is(err, ErrInteractionNotFound) {
+		t.Errorf("Expected ErrInteractionNotFound, got: %v", err)
+	}
+
+	if _, err := NewRecorder(filepath.Join(t.TempDir(), "missing.json"), RecorderOptions{}); err == nil {
+		t.Error("Expected error when replaying a missing cassette")
+	}
+}
@@ -0,0 +1,122 @@
+package scraper
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/saintfish/chardet"
+	"golang.org/x/net/html/charset"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+)
+
+// xmlDeclarationPattern matches the encoding of an XML declaration
+var xmlDeclarationPattern = regexp.MustCompile(`^<\?xml[^>]*?\sencoding\s*=\s*["']([A-Za-z0-9._:-]+)["']`)
+
+// utf8BOM is the byte order mark some servers prepend to UTF-8 bodies
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// DecodeToUTF8 transcodes body to UTF-8 and returns it with the name of the
+// charset it was decoded from, e.g. "shift_jis" or "windows-1251"
+// The charset comes from a byte order mark, the charset parameter of
+// contentType, an XML declaration or a <meta charset> tag, in that order.
+// Undeclared bodies are sniffed. A non-empty force skips detection
+func DecodeToUTF8(body []byte, contentType, force string) ([]byte, string, error) {
+	e, name, err := detectCharset(body, contentType, force)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if name != "utf-8" {
+		decoded, err := e.NewDecoder().Bytes(body)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to decode %s body: %w", name, err)
+		}
+		body = decoded
+	}
+	// Byte order marks are not part of the text, UTF-16 decoders keep theirs
+	return bytes.TrimPrefix(body, utf8BOM), name, nil
+}
+
+// detectCharset returns the encoding of body and its canonical name
+func detectCharset(body []byte, contentType, force string) (encoding.Encoding, string, error) {
+	if force != "" {
+		return lookupCharset(force)
+	}
+
+	e, name, certain := charset.DetermineEncoding(body, contentType)
+	if certain {
+		return e, name, nil
+	}
+
+	if m := xmlDeclarationPattern.FindSubmatch(body); m != nil {
+		if e, name := charset.Lookup(string(m[1])); e != nil {
+			return e, name, nil
+		}
+	}
+
+	// DetermineEncoding falls back to these two exact encodings when the
+	// content declares none, anything else came from a <meta> tag
+	if e != encoding.Nop && e != charmap.Windows1252 {
+		return e, name, nil
+	}
+	return sniffCharset(body)
+}
+
+// sniffCharset guesses the encoding of an undeclared body
+// Valid UTF-8 wins, windows-1252 is the last resort as in browsers
+func sniffCharset(body []byte) (encoding.Encoding, string, error) {
+	if utf8.Valid(body) {
+		return encoding.Nop, "utf-8", nil
+	}
+	if result, err := chardet.NewTextDetector().DetectBest(body); err == nil {
+		if e, name := charset.Lookup(result.Charset); e != nil {
+			return e, name, nil
+		}
+	}
+	return lookupCharset("windows-1252")
+}
+
+// lookupCharset returns the encoding registered under label
+func lookupCharset(label string) (encoding.Encoding, string, error) {
+	e, name := charset.Lookup(label)
+	if e == nil {
+		return nil, "", fmt.Errorf("unknown charset %q", label)
+	}
+	return e, name, nil
+}
+
+// isTextContent reports whether a response of mediaType holds text worth
+// decoding, responses without a Content-Type are assumed to be
+func isTextContent(mediaType string) bool {
+	switch {
+	case mediaType == "", strings.HasPrefix(mediaType, "text/"):
+		return true
+	case strings.HasSuffix(mediaType, "+xml"), strings.HasSuffix(mediaType, "+json"):
+		return true
+	}
+	switch mediaType {
+	case "application/xml", "application/json", "application/javascript", "application/ecmascript":
+		return true
+	}
+	return false
+}
+
+// decodeResponse transcodes the body of a text response to UTF-8 and records
+// the charset it was decoded from, honouring Options.Charset
+func (s *Scraper) decodeResponse(resp *Response) (*Response, error) {
+	if !isTextContent(resp.ContentType) {
+		return resp, nil
+	}
+
+	body, name, err := DecodeToUTF8(resp.Body, resp.Header.Get("Content-Type"), s.options.Charset)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = body
+	resp.Charset = name
+	return resp, nil
+}
@@ -0,0 +1,215 @@
+package scraper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// encode returns text encoded with e
+func encode(t *testing.T, e encoding.Encoding, text string) []byte {
+	t.Helper()
+	data, err := e.NewEncoder().Bytes([]byte(text))
+	if err != nil {
+		t.Fatalf("failed to encode %q: %v", text, err)
+	}
+	return data
+}
+
+// TestDecodeToUTF8 verifies charset detection and transcoding
+func TestDecodeToUTF8(t *testing.T) {
+	const russian = "Цена: 1 500,00 руб."
+	const japaneseText = "価格は千円です"
+	longASCII := strings.Repeat("<p>ascii</p>", 200)
+
+	tests := []struct {
+		name        string
+		body        []byte
+		contentType string
+		force       string
+		expected    string
+		charset     string
+	}{
+		{
+			"Content-Type header",
+			encode(t, japanese.ShiftJIS, "<p>"+japaneseText+"</p>"),
+			"text/html; charset=Shift_JIS",
+			"",
+			"<p>" + japaneseText + "</p>",
+			"shift_jis",
+		},
+		{
+			"Meta charset",
+			encode(t, charmap.Windows1251, `<meta charset="windows-1251"><p>`+russian+`</p>`),
+			"text/html",
+			"",
+			`<meta charset="windows-1251"><p>` + russian + `</p>`,
+			"windows-1251",
+		},
+		{
+			"Meta http-equiv",
+			encode(t, charmap.ISO8859_1, `<meta http-equiv="Content-Type" content="text/html; charset=iso-8859-1"><p>Café</p>`),
+			"",
+			"",
+			`<meta http-equiv="Content-Type" content="text/html; charset=iso-8859-1"><p>Café</p>`,
+			"windows-1252",
+		},
+		{
+			"Header wins over meta",
+			encode(t, charmap.Windows1251, `<meta charset="utf-8"><p>`+russian+`</p>`),
+			"text/html; charset=cp1251",
+			"",
+			`<meta charset="utf-8"><p>` + russian + `</p>`,
+			"windows-1251",
+		},
+		{
+			"XML declaration",
+			encode(t, japanese.EUCJP, `<?xml version="1.0" encoding="EUC-JP"?><loc>`+japaneseText+`</loc>`),
+			"application/xml",
+			"",
+			`<?xml version="1.0" encoding="EUC-JP"?><loc>` + japaneseText + `</loc>`,
+			"euc-jp",
+		},
+		{
+			"UTF-8 BOM",
+			append([]byte{0xEF, 0xBB, 0xBF}, "<p>Café</p>"...),
+			"text/html; charset=iso-8859-1",
+			"",
+			"<p>Café</p>",
+			"utf-8",
+		},
+		{
+			"UTF-16 BOM",
+			encode(t, unicode.UTF16(unicode.LittleEndian, unicode.UseBOM), "<p>"+russian+"</p>"),
+			"text/html",
+			"",
+			"<p>" + russian + "</p>",
+			"utf-16le",
+		},
+		{
+			"Sniffed UTF-8 past the first KB",
+			[]byte(longASCII + "<p>" + japaneseText + "</p>"),
+			"application/json",
+			"",
+			longASCII + "<p>" + japaneseText + "</p>",
+			"utf-8",
+		},
+		{
+			"Sniffed Shift_JIS",
+			encode(t, japanese.ShiftJIS, "<p>"+strings.Repeat(japaneseText+"。", 10)+"</p>"),
+			"text/html",
+			"",
+			"<p>" + strings.Repeat(japaneseText+"。", 10) + "</p>",
+			"shift_jis",
+		},
+		{
+			"Forced",
+			encode(t, charmap.Windows1251, russian),
+			"text/html; charset=utf-8",
+			"windows-1251",
+			russian,
+			"windows-1251",
+		},
+		{
+			"ASCII",
+			[]byte("<p>plain</p>"),
+			"text/html",
+			"",
+			"<p>plain</p>",
+			"utf-8",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, name, err := DecodeToUTF8(tt.body, tt.contentType, tt.force)
+			if err != nil {
+				t.Fatalf("DecodeToUTF8() error = %v", err)
+			}
+			if string(body) != tt.expected {
+				t.Errorf("DecodeToUTF8() body = %q, want %q", body, tt.expected)
+			}
+			if name != tt.charset {
+				t.Errorf("DecodeToUTF8() charset = %q, want %q", name, tt.charset)
+			}
+		})
+	}
+
+	if _, _, err := DecodeToUTF8([]byte("x"), "", "klingon"); err == nil {
+		t.Error("Expected an error for an unknown forced charset")
+	}
+}
+
+// TestScraper_Charset verifies fetched pages are decoded before extraction,
+// for both built-in fetchers and for cached responses
+func TestScraper_Charset(t *testing.T) {
+	page := encode(t, japanese.ShiftJIS, `<div class="price">千円</div>`)
+	image := []byte{0x89, 'P', 'N', 'G', 0x82, 0xA0}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/image.png":
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write(image)
+		case "/undeclared":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write(page)
+		default:
+			w.Header().Set("Content-Type", "text/html; charset=shift_jis")
+			w.Header().Set("Cache-Control", "max-age=60")
+			_, _ = w.Write(page)
+		}
+	}))
+	defer server.Close()
+
+	fetchers := map[string]Fetcher{
+		"colly": NewCollyFetcher(Options{UserAgent: DefaultUserAgent}),
+		"http":  NewHTTPFetcher(nil, DefaultUserAgent),
+	}
+
+	for name, fetcher := range fetchers {
+		t.Run(name, func(t *testing.T) {
+			s := New(Options{MaxRetries: 1, Fetcher: fetcher, Cache: NewMemoryCache(0)})
+
+			for _, source := range []string{"network", "cache"} {
+				resp, err := s.Fetch(server.URL + "/page")
+				if err != nil {
+					t.Fatalf("Fetch() error = %v", err)
+				}
+				if resp.FromCache != (source == "cache") {
+					t.Fatalf("Fetch() FromCache = %v on the %s fetch", resp.FromCache, source)
+				}
+				if resp.Charset != "shift_jis" {
+					t.Errorf("Charset from %s = %q, want shift_jis", source, resp.Charset)
+				}
+				texts, err := GetText(resp.HTML(), "div.price")
+				if err != nil || len(texts) != 1 || texts[0] != "千円" {
+					t.Errorf("GetText() from %s = %v, %v, want [千円]", source, texts, err)
+				}
+			}
+
+			resp, err := s.Fetch(server.URL + "/image.png")
+			if err != nil {
+				t.Fatalf("Fetch() error = %v", err)
+			}
+			if string(resp.Body) != string(image) || resp.Charset != "" {
+				t.Errorf("Image body = %q, charset %q, want it untouched", resp.Body, resp.Charset)
+			}
+
+			forced := New(Options{MaxRetries: 1, Fetcher: fetcher, Charset: "sjis"})
+			html, err := forced.ScrapeHTML(server.URL + "/undeclared")
+			if err != nil {
+				t.Fatalf("ScrapeHTML() error = %v", err)
+			}
+			if html != `<div class="price">千円</div>` {
+				t.Errorf("ScrapeHTML() with a forced charset = %q", html)
+			}
+		})
+	}
+}
@@ -0,0 +1,441 @@
+// Command go-scraper fetches pages, extracts elements with CSS selectors or
+// structured data and follows pagination from the command line.
+//
+// Usage:
+//
+//	go-scraper fetch [flags] <url>
+//	go-scraper select [flags] -s <selector> [<url>|<file>|-]
+//	go-scraper structured [flags] [--type <type>] [<url>|<file>|-]
+//	go-scraper paginate [flags] -s <selector> (--next <selector> | [--last <selector>] (--pattern <pattern> | --page-body-field <field>) | --offset-param <name> | --cursor-path <path> | --link-header) <url>
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+
+	scraper "github.com/unluckythoughts/go-scraper"
+)
+
+// Exit codes
+const (
+	exitOK         = 0
+	exitUsage      = 2
+	exitNetwork    = 3
+	exitHTTPStatus = 4
+	exitExtraction = 5
+)
+
+const usage = `go-scraper fetches pages, extracts elements or structured data and follows pagination.
+
+Usage:
+  go-scraper fetch [flags] <url>
+  go-scraper select [flags] -s <selector> [<url>|<file>|-]
+  go-scraper structured [flags] [--type <type>] [<url>|<file>|-]
+  go-scraper paginate [flags] -s <selector> (--next <selector> | [--last <selector>] (--pattern <pattern> | --page-body-field <field>) | --offset-param <name> | --cursor-path <path> | --link-header) <url>
+
+Run 'go-scraper <command> -h' for the flags of a command.
+
+Exit codes:
+  0  success
+  2  invalid usage
+  3  network failure
+  4  HTTP status failure or request refused by robots.txt or --allowed-domains
+  5  extraction failure (nothing matched or content could not be parsed)
+`
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	os.Exit(run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes the command described by args and returns the process exit code
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitUsage
+	}
+
+	switch args[0] {
+	case "fetch":
+		return runFetch(ctx, args[1:], stdout, stderr)
+	case "select":
+		return runSelect(ctx, args[1:], stdin, stdout, stderr)
+	case "paginate":
+		return runPaginate(ctx, args[1:], stdout, stderr)
+	case "structured":
+		return runStructured(ctx, args[1:], stdin, stdout, stderr)
+	case "help", "-h", "--help":
+		fmt.Fprint(stdout, usage)
+		return exitOK
+	default:
+		fmt.Fprintf(stderr, "unknown command %q\n\n%s", args[0], usage)
+		return exitUsage
+	}
+}
+
+// command holds the state shared by every subcommand
+type command struct {
+	flags  scraperFlags
+	fs     *flag.FlagSet
+	stderr io.Writer
+}
+
+func newCommand(name, args string, stderr io.Writer) *command {
+	c := &command{fs: flag.NewFlagSet(name, flag.ContinueOnError), stderr: stderr}
+	c.fs.SetOutput(stderr)
+	c.fs.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: go-scraper %s [flags] %s\n\nFlags:\n", name, args)
+		c.fs.PrintDefaults()
+	}
+	c.flags.register(c.fs)
+	return c
+}
+
+// parse parses args and reports a usage exit code on failure
+func (c *command) parse(args []string) (int, bool) {
+	if err := c.fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK, false
+		}
+		return exitUsage, false
+	}
+	return exitOK, true
+}
+
+// scraper builds the Scraper and the command context from the parsed flags
+// The returned cancel func also saves the --session cookie jar, only once
+// however often it is called
+func (c *command) scraper(ctx context.Context) (*scraper.Scraper, context.Context, context.CancelFunc, error) {
+	opts, err := c.flags.options(c.stderr)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	cancel := context.CancelFunc(func() {})
+	if c.flags.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, c.flags.timeout)
+	}
+
+	// Save the session once the command is done
+	if opts.Session != nil {
+		cancelCtx := cancel
+		var once sync.Once
+		cancel = func() {
+			once.Do(func() {
+				cancelCtx()
+				if err := opts.Session.Save(c.flags.session); err != nil {
+					fmt.Fprintf(c.stderr, "error: %v\n", err)
+				}
+			})
+		}
+	}
+
+	return scraper.New(opts), ctx, cancel, nil
+}
+
+// usageError prints err with the command usage and returns the usage exit code
+func (c *command) usageError(err error) int {
+	fmt.Fprintf(c.stderr, "error: %v\n", err)
+	c.fs.Usage()
+	return exitUsage
+}
+
+// fetchError prints err and returns its exit code
+func (c *command) fetchError(err error) int {
+	fmt.Fprintf(c.stderr, "error: %v\n", err)
+	return exitCode(err)
+}
+
+// exitCode maps a scraper error to an exit code
+func exitCode(err error) int {
+	var statusErr *scraper.HTTPStatusError
+	var extractionErr *scraper.ExtractionError
+	switch {
+	case errors.As(err, &extractionErr):
+		return exitExtraction
+	case errors.As(err, &statusErr),
+		errors.Is(err, scraper.ErrDisallowedByRobots),
+		errors.Is(err, scraper.ErrDomainNotAllowed):
+		return exitHTTPStatus
+	default:
+		return exitNetwork
+	}
+}
+
+func runFetch(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	c := newCommand("fetch", "<url>", stderr)
+	if code, ok := c.parse(args); !ok {
+		return code
+	}
+	if c.fs.NArg() != 1 {
+		return c.usageError(errors.New("exactly one URL is required"))
+	}
+	req, err := c.flags.request(c.fs.Arg(0))
+	if err != nil {
+		return c.usageError(err)
+	}
+
+	s, ctx, cancel, err := c.scraper(ctx)
+	if err != nil {
+		return c.usageError(err)
+	}
+	defer cancel()
+
+	resp, err := s.FetchRequest(ctx, req)
+	if err != nil {
+		return c.fetchError(err)
+	}
+
+	fmt.Fprint(stdout, resp.HTML())
+	return exitOK
+}
+
+// input reads the page named by the optional argument: a URL, a file or
+// stdin for "-" or no argument. Local pages are decoded to UTF-8 like fetched ones
+func (c *command) input(ctx context.Context, stdin io.Reader) (*scraper.Response, int) {
+	source := "-"
+	if c.fs.NArg() == 1 {
+		source = c.fs.Arg(0)
+	}
+
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		req, err := c.flags.request(source)
+		if err != nil {
+			return nil, c.usageError(err)
+		}
+		s, ctx, cancel, err := c.scraper(ctx)
+		if err != nil {
+			return nil, c.usageError(err)
+		}
+		defer cancel()
+
+		resp, err := s.FetchRequest(ctx, req)
+		if err != nil {
+			return nil, c.fetchError(err)
+		}
+		return resp, exitOK
+	}
+
+	var data []byte
+	var err error
+	if source == "-" {
+		if data, err = io.ReadAll(stdin); err != nil {
+			err = fmt.Errorf("failed to read stdin: %w", err)
+		}
+	} else {
+		data, err = os.ReadFile(source)
+	}
+	if err == nil {
+		data, _, err = scraper.DecodeToUTF8(data, "", c.flags.charset)
+	}
+	if err != nil {
+		fmt.Fprintf(c.stderr, "error: %v\n", err)
+		return nil, exitUsage
+	}
+	return &scraper.Response{Body: data}, exitOK
+}
+
+func runSelect(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	c := newCommand("select", "-s <selector> [<url>|<file>|-]", stderr)
+	selector := c.fs.String("s", "", "CSS selector, use || to combine several selectors")
+	text := c.fs.Bool("text", false, "print text content (or attribute value) instead of outer HTML")
+	jsonl := c.fs.Bool("jsonl", false, "print each match as a JSON string on its own line")
+	if code, ok := c.parse(args); !ok {
+		return code
+	}
+	if *selector == "" {
+		return c.usageError(errors.New("-s selector is required"))
+	}
+	if c.fs.NArg() > 1 {
+		return c.usageError(errors.New("at most one input is allowed"))
+	}
+
+	resp, code := c.input(ctx, stdin)
+	if code != exitOK {
+		return code
+	}
+	html := resp.HTML()
+
+	var matches []string
+	var err error
+	if *text {
+		matches, err = scraper.GetText(html, *selector)
+	} else {
+		matches, err = scraper.GetOuterHTML(html, *selector)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return exitExtraction
+	}
+
+	for _, match := range matches {
+		if err := writeLine(stdout, match, *jsonl); err != nil {
+			fmt.Fprintf(stderr, "error: %v\n", err)
+			return exitUsage
+		}
+	}
+
+	if len(matches) == 0 {
+		fmt.Fprintf(stderr, "error: selector %q matched nothing\n", *selector)
+		return exitExtraction
+	}
+	return exitOK
+}
+
+// paginateRecord is a single JSONL line written by the paginate command
+type paginateRecord struct {
+	Data  string `json:"data,omitempty"`
+	Error string `json:"error,omitempty"`
+	URL   string `json:"url,omitempty"`
+	Page  int    `json:"page,omitempty"`
+	Index int    `json:"index"`
+	Proxy string `json:"proxy,omitempty"`
+}
+
+func runStructured(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	c := newCommand("structured", "[--type <type>] [<url>|<file>|-]", stderr)
+	typ := c.fs.String("type", "", "print every entity of this schema.org type as JSONL, e.g. Product")
+	if code, ok := c.parse(args); !ok {
+		return code
+	}
+	if c.fs.NArg() > 1 {
+		return c.usageError(errors.New("at most one input is allowed"))
+	}
+
+	resp, code := c.input(ctx, stdin)
+	if code != exitOK {
+		return code
+	}
+	doc, err := scraper.NewDocumentFromResponse(resp)
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return exitExtraction
+	}
+	data := doc.StructuredData()
+
+	encoder := json.NewEncoder(stdout)
+	encoder.SetEscapeHTML(false)
+	if *typ == "" {
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(data); err != nil {
+			fmt.Fprintf(stderr, "error: %v\n", err)
+			return exitUsage
+		}
+		return exitOK
+	}
+
+	entities := data.All(*typ)
+	if len(entities) == 0 {
+		fmt.Fprintf(stderr, "error: no %s entity found\n", *typ)
+		return exitExtraction
+	}
+	for _, entity := range entities {
+		if err := encoder.Encode(entity); err != nil {
+			fmt.Fprintf(stderr, "error: %v\n", err)
+			return exitUsage
+		}
+	}
+	return exitOK
+}
+
+func runPaginate(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	c := newCommand("paginate", "-s <selector> (--next <selector> | [--last <selector>] (--pattern <pattern> | --page-body-field <field>) | --offset-param <name> | --cursor-path <path> | --link-header) <url>", stderr)
+	selector := c.fs.String("s", "", "CSS selector of the items to extract, a JSON path for JSON responses")
+	next := c.fs.String("next", "", "CSS selector of the next page link (sequential mode)")
+	last := c.fs.String("last", "", "CSS selector of the last page number (parallel mode)")
+	pattern := c.fs.String("pattern", "", "page URL pattern with a ::page:: placeholder (parallel mode, open-ended without --last)")
+	maxPages := c.fs.Int("max-pages", 0, "stop after this many pages")
+	ordered := c.fs.Bool("ordered", false, "write results in page order (parallel mode)")
+	offsetParam := c.fs.String("offset-param", "", "query parameter holding the item offset (offset mode)")
+	limitParam := c.fs.String("limit-param", "", "query parameter holding the page size (offset mode)")
+	limit := c.fs.Int("limit", 0, "page size and offset step (offset mode, default: items per page)")
+	cursorPath := c.fs.String("cursor-path", "", "JSON path of the next cursor in the response (cursor mode)")
+	cursorParam := c.fs.String("cursor-param", "", "query parameter for the cursor, if empty the cursor is the next URL (cursor mode)")
+	linkHeader := c.fs.Bool("link-header", false, `follow the rel="next" Link response header`)
+	pageBodyField := c.fs.String("page-body-field", "", "form field or JSON path of the --data or --json body set to the page number (parallel mode, open-ended without --last)")
+	if code, ok := c.parse(args); !ok {
+		return code
+	}
+	if *selector == "" {
+		return c.usageError(errors.New("-s selector is required"))
+	}
+	if c.fs.NArg() != 1 {
+		return c.usageError(errors.New("exactly one URL is required"))
+	}
+	req, err := c.flags.request(c.fs.Arg(0))
+	if err != nil {
+		return c.usageError(err)
+	}
+
+	s, ctx, cancel, err := c.scraper(ctx)
+	if err != nil {
+		return c.usageError(err)
+	}
+	defer cancel()
+
+	config := scraper.PaginationConfig{
+		NextPageSelector:   *next,
+		LastPageSelector:   *last,
+		NextPageURLPattern: *pattern,
+		Ordered:            *ordered,
+		MaxPages:           *maxPages,
+		OffsetParam:        *offsetParam,
+		LimitParam:         *limitParam,
+		Limit:              *limit,
+		CursorPath:         *cursorPath,
+		CursorParam:        *cursorParam,
+		FollowLinkHeader:   *linkHeader,
+		PageBodyField:      *pageBodyField,
+	}
+	results, err := s.ScrapePaginatedRequest(ctx, req, *selector, config)
+	if err != nil {
+		return c.usageError(err)
+	}
+
+	encoder := json.NewEncoder(stdout)
+	encoder.SetEscapeHTML(false)
+
+	code := exitOK
+	for result := range results {
+		record := paginateRecord{Data: result.Data, URL: result.URL, Page: result.Page, Index: result.Index, Proxy: result.Proxy}
+		if result.Err != nil {
+			record.Error = result.Err.Error()
+			fmt.Fprintf(stderr, "error: %v\n", result.Err)
+			code = max(code, exitCode(result.Err))
+		}
+		if err := encoder.Encode(record); err != nil {
+			fmt.Fprintf(stderr, "error: %v\n", err)
+			cancel()
+			for range results {
+			}
+			return exitUsage
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return max(code, exitNetwork)
+	}
+	return code
+}
+
+// writeLine prints value on its own line, JSON encoded if requested
+func writeLine(w io.Writer, value string, asJSON bool) error {
+	if asJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetEscapeHTML(false)
+		return encoder.Encode(value)
+	}
+	_, err := fmt.Fprintln(w, value)
+	return err
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			_, _ = w.Write([]byte(`<div class="item">One</div><a class="next" href="/page2">Next</a><span class="pages">2</span>`))
+		case "/page2":
+			_, _ = w.Write([]byte(`<div class="item">Two</div>`))
+		case "/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func runCLI(stdin string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+// TestFetch verifies raw HTML output, verbose logging and exit codes for failures
+func TestFetch(t *testing.T) {
+	server := newTestServer()
+	defer server.Close()
+
+	code, stdout, _ := runCLI("", "fetch", "--max-retries", "1", server.URL+"/")
+	if code != exitOK || !strings.Contains(stdout, `<div class="item">One</div>`) {
+		t.Errorf("fetch = %d, %q", code, stdout)
+	}
+
+	code, _, stderr := runCLI("", "fetch", "--max-retries", "1", "--verbose", server.URL+"/")
+	if code != exitOK || !strings.Contains(stderr, "attempt 1 "+server.URL+"/ status=200") {
+		t.Errorf("fetch --verbose = %d, stderr %q", code, stderr)
+	}
+
+	code, _, _ = runCLI("", "fetch", "--max-retries", "1", server.URL+"/missing")
+	if code != exitHTTPStatus {
+		t.Errorf("fetch of 404 = %d, want %d", code, exitHTTPStatus)
+	}
+
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	code, _, _ = runCLI("", "fetch", "--max-retries", "1", closed.URL)
+	if code != exitNetwork {
+		t.Errorf("fetch of closed server = %d, want %d", code, exitNetwork)
+	}
+
+	code, _, _ = runCLI("", "fetch")
+	if code != exitUsage {
+		t.Errorf("fetch without URL = %d, want %d", code, exitUsage)
+	}
+}
+
+// TestSelect verifies selection over stdin, files and URLs
+func TestSelect(t *testing.T) {
+	server := newTestServer()
+	defer server.Close()
+
+	html := `<p>First</p><p>Second</p><a href="/x">link</a>`
+
+	code, stdout, _ := runCLI(html, "select", "-s", "p", "--text")
+	if code != exitOK || stdout != "First\nSecond\n" {
+		t.Errorf("select from stdin = %d, %q", code, stdout)
+	}
+
+	code, stdout, _ = runCLI(html, "select", "-s", "h1||a[href]", "--text", "-")
+	if code != exitOK || stdout != "/x\n" {
+		t.Errorf("select with || = %d, %q", code, stdout)
+	}
+
+	path := filepath.Join(t.TempDir(), "page.html")
+	if err := os.WriteFile(path, []byte(html), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	code, stdout, _ = runCLI("", "select", "-s", "p", "--jsonl", path)
+	if code != exitOK || stdout != "\"<p>First</p>\"\n\"<p>Second</p>\"\n" {
+		t.Errorf("select from file = %d, %q", code, stdout)
+	}
+
+	code, stdout, _ = runCLI("", "select", "-s", "div.item", "--max-retries", "1", server.URL+"/")
+	if code != exitOK || !strings.Contains(stdout, "One") {
+		t.Errorf("select from URL = %d, %q", code, stdout)
+	}
+
+	code, _, _ = runCLI(html, "select", "-s", "table")
+	if code != exitExtraction {
+		t.Errorf("select with no match = %d, want %d", code, exitExtraction)
+	}
+
+	// "Café" in windows-1252, declared by a meta tag or forced with --charset
+	latin1 := "<p>Caf\xe9</p>"
+	code, stdout, _ = runCLI(`<meta charset="windows-1252">`+latin1, "select", "-s", "p", "--text")
+	if code != exitOK || stdout != "Café\n" {
+		t.Errorf("select from windows-1252 stdin = %d, %q", code, stdout)
+	}
+	code, stdout, _ = runCLI(latin1, "select", "-s", "p", "--text", "--charset", "latin1")
+	if code != exitOK || stdout != "Café\n" {
+		t.Errorf("select with --charset = %d, %q", code, stdout)
+	}
+
+	code, _, _ = runCLI("", "select", "-s", "p", "--max-retries", "1", server.URL+"/broken")
+	if code != exitHTTPStatus {
+		t.Errorf("select from failing URL = %d, want %d", code, exitHTTPStatus)
+	}
+}
+
+// TestStructured verifies structured data output and --type filtering
+func TestStructured(t *testing.T) {
+	html := `<script type="application/ld+json">{"@type": "Product", "name": "Widget"}</script>
+		<meta property="og:title" content="Widget page">`
+
+	code, stdout, _ := runCLI(html, "structured")
+	var data struct {
+		JSONLD    []map[string]any    `json:"json_ld"`
+		OpenGraph map[string][]string `json:"open_graph"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &data); code != exitOK || err != nil {
+		t.Fatalf("structured = %d, %q, %v", code, stdout, err)
+	}
+	if len(data.JSONLD) != 1 || data.JSONLD[0]["name"] != "Widget" || data.OpenGraph["og:title"][0] != "Widget page" {
+		t.Errorf("structured output = %+v", data)
+	}
+
+	code, stdout, _ = runCLI(html, "structured", "--type", "Product")
+	if code != exitOK || stdout != `{"@type":"Product","name":"Widget"}`+"\n" {
+		t.Errorf("structured --type Product = %d, %q", code, stdout)
+	}
+
+	code, _, _ = runCLI(html, "structured", "--type", "Article")
+	if code != exitExtraction {
+		t.Errorf("structured --type with no match = %d, want %d", code, exitExtraction)
+	}
+}
+
+// TestPaginate verifies both pagination modes stream JSONL
+func TestPaginate(t *testing.T) {
+	server := newTestServer()
+	defer server.Close()
+
+	modes := map[string][]string{
+		"Sequential": {"--next", "a.next[href]"},
+		"Parallel":   {"--last", "span.pages", "--pattern", "/page::page::"},
+		"Ordered":    {"--last", "span.pages", "--pattern", "/page::page::", "--ordered"},
+	}
+
+	for name, flags := range modes {
+		t.Run(name, func(t *testing.T) {
+			args := append([]string{"paginate", "-s", "div.item", "--max-retries", "1", "--delay", "1ms"}, flags...)
+			code, stdout, stderr := runCLI("", append(args, server.URL+"/")...)
+			if code != exitOK {
+				t.Fatalf("paginate = %d, stderr: %s", code, stderr)
+			}
+
+			lines := strings.Split(strings.TrimSpace(stdout), "\n")
+			if len(lines) != 2 {
+				t.Fatalf("Expected 2 JSONL lines, got %d: %q", len(lines), stdout)
+			}
+			var record paginateRecord
+			if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+				t.Fatalf("Invalid JSONL line %q: %v", lines[0], err)
+			}
+			if !strings.Contains(record.Data, "One") && !strings.Contains(record.Data, "Two") {
+				t.Errorf("Unexpected record %+v", record)
+			}
+			if record.Page < 1 || !strings.HasPrefix(record.URL, server.URL) {
+				t.Errorf("Expected page metadata, got %+v", record)
+			}
+		})
+	}
+
+	code, _, _ := runCLI("", "paginate", "-s", "div.item", "--last", "span.pages", server.URL+"/")
+	if code != exitUsage {
+		t.Errorf("paginate without pattern = %d, want %d", code, exitUsage)
+	}
+
+	code, _, _ = runCLI("", "paginate", "-s", "div.item", "--max-retries", "1", server.URL+"/missing")
+	if code != exitHTTPStatus {
+		t.Errorf("paginate of 404 = %d, want %d", code, exitHTTPStatus)
+	}
+}
+
+// TestUnknownCommand verifies usage errors
+func TestUnknownCommand(t *testing.T) {
+	if code, _, _ := runCLI("", "bogus"); code != exitUsage {
+		t.Errorf("unknown command = %d, want %d", code, exitUsage)
+	}
+	if code, _, _ := runCLI(""); code != exitUsage {
+		t.Errorf("no command = %d, want %d", code, exitUsage)
+	}
+	if code, _, _ := runCLI("", "fetch", "--jitter", "wild", "https://example.com"); code != exitUsage {
+		t.Errorf("invalid flag value = %d, want %d", code, exitUsage)
+	}
+	if code, _, _ := runCLI("", "fetch", "--proxy", "proxy.example.com:8080", "https://example.com"); code != exitUsage {
+		t.Errorf("invalid proxy = %d, want %d", code, exitUsage)
+	}
+}
+
+// TestSession verifies cookies survive between runs through the --session file
+func TestSession(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/set" {
+			http.SetCookie(w, &http.Cookie{Name: "sid", Value: "abc", MaxAge: 3600})
+		}
+		if cookie, err := r.Cookie("sid"); err == nil {
+			_, _ = w.Write([]byte("sid=" + cookie.Value))
+		}
+	}))
+	defer server.Close()
+
+	session := filepath.Join(t.TempDir(), "session.json")
+	if code, _, stderr := runCLI("", "fetch", "--session", session, server.URL+"/set"); code != exitOK {
+		t.Fatalf("fetch = %d: %s", code, stderr)
+	}
+	code, stdout, _ := runCLI("", "fetch", "--session", session, server.URL+"/check")
+	if code != exitOK || stdout != "sid=abc" {
+		t.Errorf("fetch with saved session = %d, %q", code, stdout)
+	}
+}
+
+// TestRequestFlags verifies methods, headers and bodies are sent as given
+func TestRequestFlags(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_, _ = w.Write([]byte(r.Method + "|" + r.Header.Get("X-Api-Key") + "|" + r.Header.Get("Content-Type") + "|" + string(body)))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{"Header", []string{"--header", "X-Api-Key: secret"}, "GET|secret||"},
+		{"Form", []string{"--data", "q=shoes"}, "POST||application/x-www-form-urlencoded|q=shoes"},
+		{"JSON", []string{"--method", "put", "--json", `{"q":"shoes"}`}, `PUT||application/json|{"q":"shoes"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"fetch", "--max-retries", "1"}, tt.args...)
+			code, stdout, stderr := runCLI("", append(args, server.URL)...)
+			if code != exitOK || stdout != tt.expected {
+				t.Errorf("fetch = %d, %q (%s), want %q", code, stdout, stderr, tt.expected)
+			}
+		})
+	}
+
+	if code, _, _ := runCLI("", "fetch", "--json", "{", server.URL); code != exitUsage {
+		t.Errorf("invalid --json = %d, want %d", code, exitUsage)
+	}
+	if code, _, _ := runCLI("", "fetch", "--header", "no-colon", server.URL); code != exitUsage {
+		t.Errorf("invalid --header = %d, want %d", code, exitUsage)
+	}
+}
@@ -0,0 +1,204 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// DefaultLinkSelector selects the links followed by Crawl
+const DefaultLinkSelector = "a[href]"
+
+// CrawlHandler extracts results from a crawled page
+// depth is 0 for seed URLs and grows by one for every link followed
+// Each returned string is sent as a Result, a non-nil error is sent as well
+type CrawlHandler func(doc *Document, depth int) ([]string, error)
+
+// CrawlConfig holds configuration for link-following crawls
+// Options.MaxDepth limits the link depth (0 means unlimited) and
+// Options.AllowedDomains the hosts crawled (empty means any)
+type CrawlConfig struct {
+	// LinkSelector selects the links to follow, defaults to "a[href]"
+	// Use a scoped selector like "nav a[href] || div.content a[href]" to
+	// only follow links found in parts of the page
+	LinkSelector string
+	// Include restricts followed links to URLs matching at least one regex
+	Include []string
+	// Exclude skips links whose URL matches any regex
+	Exclude []string
+	// MaxPages stops the crawl after this many pages, 0 means unlimited
+	MaxPages int
+	// Handler is called for every crawled page, if nil the page URL is sent
+	Handler CrawlHandler
+	// Frontier tracks discovered pages, defaults to a new in-memory frontier
+	// Pass a frontier from NewFileFrontier to resume an interrupted crawl
+	Frontier Frontier
+}
+
+// crawlFilter decides which discovered links are followed
+type crawlFilter struct {
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+	domains map[string]bool
+}
+
+func compilePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+func newCrawlFilter(config CrawlConfig, allowedDomains []string) (*crawlFilter, error) {
+	include, err := compilePatterns(config.Include)
+	if err != nil {
+		return nil, err
+	}
+	exclude, err := compilePatterns(config.Exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &crawlFilter{include: include, exclude: exclude}
+	if len(allowedDomains) > 0 {
+		f.domains = make(map[string]bool, len(allowedDomains))
+		for _, domain := range allowedDomains {
+			f.domains[strings.ToLower(domain)] = true
+		}
+	}
+	return f, nil
+}
+
+// allowed reports whether the host of u may be crawled
+func (f *crawlFilter) allowed(u *url.URL) bool {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return false
+	}
+	return f.domains == nil || f.domains[strings.ToLower(u.Hostname())]
+}
+
+// follow reports whether a discovered link should be crawled
+func (f *crawlFilter) follow(link string) bool {
+	for _, re := range f.exclude {
+		if re.MatchString(link) {
+			return false
+		}
+	}
+	if len(f.include) == 0 {
+		return true
+	}
+	for _, re := range f.include {
+		if re.MatchString(link) {
+			return true
+		}
+	}
+	return false
+}
+
+// Crawl fetches the seed URLs and follows links matching config, streaming the
+// handler results. The channel is closed once the frontier is exhausted
+func (s *Scraper) Crawl(seeds []string, config CrawlConfig) (<-chan Result, error) {
+	return s.CrawlContext(context.Background(), seeds, config)
+}
+
+// CrawlContext is like Crawl but stops early and closes the channel when ctx is cancelled
+func (s *Scraper) CrawlContext(ctx context.Context, seeds []string, config CrawlConfig) (<-chan Result, error) {
+	if len(seeds) == 0 {
+		return nil, fmt.Errorf("at least one seed URL is required")
+	}
+	filter, err := newCrawlFilter(config, s.options.AllowedDomains)
+	if err != nil {
+		return nil, err
+	}
+	if config.LinkSelector == "" {
+		config.LinkSelector = DefaultLinkSelector
+	}
+
+	resultsChan := make(chan Result)
+	go s.crawl(ctx, seeds, config, filter, resultsChan)
+	return resultsChan, nil
+}
+
+// crawl queues the seeds and drains the frontier with a bounded pool of workers
+func (s *Scraper) crawl(ctx context.Context, seeds []string, config CrawlConfig, filter *crawlFilter, resultsChan chan<- Result) {
+	defer close(resultsChan)
+
+	frontier := config.Frontier
+	if frontier == nil {
+		frontier = NewMemoryFrontier()
+	}
+
+	// Seeds already known to a resumed frontier are not queued again
+	for _, seed := range seeds {
+		if u, err := url.Parse(seed); err != nil || !filter.allowed(u) {
+			continue
+		}
+		if _, err := frontier.Add(seed, 0); err != nil {
+			send(ctx, resultsChan, Result{Err: fmt.Errorf("failed to update frontier: %w", err)})
+			return
+		}
+	}
+
+	err := drainFrontier(ctx, frontier, s.options.MaxParallelRequests, config.MaxPages, func(entry *FrontierEntry) ([]string, error) {
+		return s.crawlPage(ctx, entry, config, filter, resultsChan)
+	})
+	if err != nil {
+		send(ctx, resultsChan, Result{Err: fmt.Errorf("failed to update frontier: %w", err)})
+	}
+}
+
+// crawlPage fetches a single page, sends the handler results and returns the
+// links to follow from it
+func (s *Scraper) crawlPage(ctx context.Context, entry *FrontierEntry, config CrawlConfig, filter *crawlFilter, resultsChan chan<- Result) ([]string, error) {
+	resp, err := s.FetchContext(ctx, entry.URL)
+	if err != nil {
+		if ctx.Err() == nil {
+			send(ctx, resultsChan, Result{URL: entry.URL, Err: fmt.Errorf("failed to crawl page %s: %w", entry.URL, err)})
+		}
+		return nil, err
+	}
+	doc, err := NewDocumentFromResponse(resp)
+	if err != nil {
+		send(ctx, resultsChan, Result{URL: entry.URL, Err: fmt.Errorf("failed to crawl page %s: %w", entry.URL, err)})
+		return nil, err
+	}
+
+	if config.Handler == nil {
+		if !send(ctx, resultsChan, pageResult(resp, 0, 0, doc.URL())) {
+			return nil, ctx.Err()
+		}
+	} else {
+		data, err := config.Handler(doc, entry.Depth)
+		for i, d := range data {
+			if !send(ctx, resultsChan, pageResult(resp, 0, i, d)) {
+				return nil, ctx.Err()
+			}
+		}
+		if err != nil {
+			r := pageResult(resp, 0, len(data), "")
+			r.Err = fmt.Errorf("failed to handle page %s: %w", entry.URL, err)
+			if !send(ctx, resultsChan, r) {
+				return nil, ctx.Err()
+			}
+		}
+	}
+
+	if s.options.MaxDepth > 0 && entry.Depth >= s.options.MaxDepth {
+		return nil, nil
+	}
+
+	var links []string
+	for _, link := range doc.GetURLs(config.LinkSelector) {
+		if u, err := url.Parse(link); err == nil && filter.allowed(u) && filter.follow(link) {
+			links = append(links, link)
+		}
+	}
+	return links, nil
+}
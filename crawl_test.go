@@ -0,0 +1,183 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// newCrawlServer serves a small site where every page links to its children
+// and back to the home page
+func newCrawlServer(hits *int32) *httptest.Server {
+	links := map[string][]string{
+		"/":          {"/a", "/b#top", "/b", "https://other.example/x", "mailto:me@example.com"},
+		"/a":         {"/", "/a/1", "/private/a"},
+		"/b":         {"/B/../b", "/b/1"},
+		"/a/1":       {"/a/1/deep"},
+		"/b/1":       {},
+		"/private/a": {},
+		"/a/1/deep":  {},
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(hits, 1)
+		children, ok := links[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var body strings.Builder
+		fmt.Fprintf(&body, "<h1>%s</h1><nav><a href=\"/\">home</a></nav><main>", r.URL.Path)
+		for _, child := range children {
+			fmt.Fprintf(&body, "<a href=%q>link</a>", child)
+		}
+		body.WriteString("</main>")
+		_, _ = w.Write([]byte(body.String()))
+	}))
+}
+
+// TestCrawl verifies depth limits, filters and deduplication
+func TestCrawl(t *testing.T) {
+	tests := []struct {
+		name     string
+		maxDepth int
+		config   CrawlConfig
+		expected []string
+	}{
+		{
+			"Unlimited depth",
+			0,
+			CrawlConfig{},
+			[]string{"/", "/a", "/a/1", "/a/1/deep", "/b", "/b/1", "/private/a"},
+		},
+		{
+			"Max depth",
+			1,
+			CrawlConfig{},
+			[]string{"/", "/a", "/b"},
+		},
+		{
+			"Exclude",
+			0,
+			CrawlConfig{Exclude: []string{`/private/`}},
+			[]string{"/", "/a", "/a/1", "/a/1/deep", "/b", "/b/1"},
+		},
+		{
+			"Include",
+			0,
+			CrawlConfig{Include: []string{`:\d+/a(/|$)`}},
+			[]string{"/", "/a", "/a/1", "/a/1/deep"},
+		},
+		{
+			"Link scope",
+			0,
+			CrawlConfig{LinkSelector: "nav a[href]"},
+			[]string{"/"},
+		},
+		{
+			"Max pages",
+			0,
+			CrawlConfig{MaxPages: 2},
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var hits int32
+			server := newCrawlServer(&hits)
+			defer server.Close()
+
+			s := New(Options{MaxRetries: 1, MaxDepth: tt.maxDepth, AllowedDomains: []string{"127.0.0.1"}})
+			tt.config.Handler = func(doc *Document, depth int) ([]string, error) {
+				return []string{doc.GetTextSingle("h1")}, nil
+			}
+			resultsChan, err := s.Crawl([]string{server.URL + "/"}, tt.config)
+			if err != nil {
+				t.Fatalf("Crawl() error = %v", err)
+			}
+
+			var pages []string
+			for result := range resultsChan {
+				if result.Err != nil {
+					t.Errorf("Received error from channel: %v", result.Err)
+					continue
+				}
+				pages = append(pages, result.Data)
+			}
+			sort.Strings(pages)
+
+			if tt.config.MaxPages > 0 {
+				if len(pages) != tt.config.MaxPages || int(hits) != tt.config.MaxPages {
+					t.Errorf("Expected %d pages and requests, got %v and %d", tt.config.MaxPages, pages, hits)
+				}
+				return
+			}
+			if !equalStrings(pages, tt.expected) {
+				t.Errorf("Crawled %v, want %v", pages, tt.expected)
+			}
+			if int(hits) != len(tt.expected) {
+				t.Errorf("Expected %d requests, got %d", len(tt.expected), hits)
+			}
+		})
+	}
+}
+
+// TestCrawl_Depth verifies the handler receives the link depth of each page
+func TestCrawl_Depth(t *testing.T) {
+	var hits int32
+	server := newCrawlServer(&hits)
+	defer server.Close()
+
+	s := New(Options{MaxRetries: 1, MaxParallelRequests: 2, AllowedDomains: []string{"127.0.0.1"}})
+	resultsChan, err := s.Crawl([]string{server.URL + "/a/1", server.URL + "/a/1"}, CrawlConfig{
+		Handler: func(doc *Document, depth int) ([]string, error) {
+			return []string{fmt.Sprintf("%s=%d", doc.GetTextSingle("h1"), depth)}, nil
+		},
+		Exclude: []string{`/b`},
+	})
+	if err != nil {
+		t.Fatalf("Crawl() error = %v", err)
+	}
+
+	var pages []string
+	for result := range resultsChan {
+		pages = append(pages, result.Data)
+	}
+	sort.Strings(pages)
+
+	expected := []string{"/=1", "/a/1/deep=1", "/a/1=0", "/a=2", "/private/a=3"}
+	if !equalStrings(pages, expected) {
+		t.Errorf("Crawled %v, want %v", pages, expected)
+	}
+}
+
+// TestCrawl_Cancel verifies the results channel closes when the context is cancelled
+func TestCrawl_Cancel(t *testing.T) {
+	var hits int32
+	server := newCrawlServer(&hits)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := New(Options{MaxRetries: 1})
+	resultsChan, err := s.CrawlContext(ctx, []string{server.URL + "/"}, CrawlConfig{})
+	if err != nil {
+		t.Fatalf("CrawlContext() error = %v", err)
+	}
+
+	<-resultsChan
+	cancel()
+	for range resultsChan {
+	}
+
+	if _, err := s.Crawl(nil, CrawlConfig{}); err == nil {
+		t.Error("Expected error without seeds")
+	}
+	if _, err := s.Crawl([]string{server.URL}, CrawlConfig{Include: []string{"("}}); err == nil {
+		t.Error("Expected error for invalid pattern")
+	}
+}
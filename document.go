@@ -0,0 +1,230 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Document is an HTML document parsed once for repeated extraction
+// Sub-documents returned by Find are scoped to a single element
+type Document struct {
+	sel  *goquery.Selection
+	url  string
+	base string
+}
+
+// NewDocument parses HTML text into a Document
+func NewDocument(htmlText string) (*Document, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(htmlText))
+	if err != nil {
+		return nil, err
+	}
+	d := &Document{sel: doc.Selection}
+	d.setURL("")
+	return d, nil
+}
+
+// NewDocumentFromResponse parses the body of resp into a Document
+// The final URL of the response is kept as the document URL
+func NewDocumentFromResponse(resp *Response) (*Document, error) {
+	doc, err := NewDocument(resp.HTML())
+	if err != nil {
+		return nil, err
+	}
+	doc.setURL(resp.URL)
+	return doc, nil
+}
+
+// NewDocumentFromSelection wraps an existing goquery selection
+func NewDocumentFromSelection(sel *goquery.Selection, url string) *Document {
+	d := &Document{sel: sel}
+	d.setURL(url)
+	return d
+}
+
+// setURL records the document URL and derives the base URL from <base href>
+func (d *Document) setURL(u string) {
+	d.url = u
+	d.base = u
+
+	root := d.sel.Closest("html")
+	if root.Length() == 0 {
+		root = d.sel
+	}
+	if href, ok := root.Find("base[href]").First().Attr("href"); ok {
+		d.base = GetFullURL(u, href)
+	}
+}
+
+// URL returns the URL the document was fetched from, empty if unknown
+func (d *Document) URL() string {
+	return d.url
+}
+
+// BaseURL returns the URL relative links are resolved against
+// This is the <base href> of the document if present, the document URL otherwise
+func (d *Document) BaseURL() string {
+	return d.base
+}
+
+// ResolveURL resolves a link found in the document against its base URL
+func (d *Document) ResolveURL(ref string) string {
+	return GetFullURL(d.base, ref)
+}
+
+// Selection returns the underlying goquery selection for advanced queries
+func (d *Document) Selection() *goquery.Selection {
+	return d.sel
+}
+
+// HTML returns the outer HTML of the document scope
+func (d *Document) HTML() string {
+	if d.sel.Is("*") {
+		html, _ := goquery.OuterHtml(d.sel)
+		return html
+	}
+	// The root document node has no outer HTML of its own
+	html, _ := d.sel.Html()
+	return html
+}
+
+// Text returns the trimmed text content of the document scope
+func (d *Document) Text() string {
+	return strings.TrimSpace(d.sel.Text())
+}
+
+// Attr returns the value of an attribute of the scoped element
+func (d *Document) Attr(name string) (string, bool) {
+	return d.sel.Attr(name)
+}
+
+// Find returns a sub-document for each element matching selector, honouring "||"
+func (d *Document) Find(selector string) []*Document {
+	var docs []*Document
+	eachResult(d.sel, selector, func(_ int, s *goquery.Selection) {
+		docs = append(docs, &Document{sel: s, url: d.url, base: d.base})
+	})
+	return docs
+}
+
+// GetOuterHTML returns the outer HTML of every element matching selector
+func (d *Document) GetOuterHTML(selector string) []string {
+	if selector == "" {
+		return []string{}
+	}
+	var results []string
+	eachResult(d.sel, selector, gethtmls(&results))
+	return results
+}
+
+// GetText returns the text content (or attribute value for attribute selectors)
+// of every element matching selector
+func (d *Document) GetText(selector string) []string {
+	if selector == "" {
+		return []string{}
+	}
+	var results []string
+	eachResult(d.sel, selector, getTexts(&results, selector, false))
+	return results
+}
+
+// GetTextSingle returns the text of the first element matching selector
+// Returns empty string if no match found
+func (d *Document) GetTextSingle(selector string) string {
+	if selector == "" {
+		return ""
+	}
+	var results []string
+	eachResult(d.sel, selector, getTexts(&results, selector, true))
+	if len(results) == 0 {
+		return ""
+	}
+	return results[0]
+}
+
+// GetURLs returns the links of every element matching selector resolved against
+// the base URL, e.g. "a[href]" or "img[src]"
+func (d *Document) GetURLs(selector string) []string {
+	links := d.GetText(selector)
+	for i, link := range links {
+		links[i] = d.ResolveURL(link)
+	}
+	return links
+}
+
+// GetURL returns the link of the first element matching selector resolved
+// against the base URL. Returns empty string if no match found
+func (d *Document) GetURL(selector string) string {
+	link := d.GetTextSingle(selector)
+	if link == "" {
+		return ""
+	}
+	return d.ResolveURL(link)
+}
+
+// GetInt returns the first element matching selector converted to int
+// Returns 0 if no match found
+func (d *Document) GetInt(selector string) (int, error) {
+	floatVal, err := d.GetFloat(selector)
+	if err != nil {
+		return 0, err
+	}
+	return int(floatVal), nil
+}
+
+// GetFloat returns the first element matching selector converted to float64
+// Returns 0.0 if no match found and an *ExtractionError if the text is not a number
+func (d *Document) GetFloat(selector string) (float64, error) {
+	text := d.GetTextSingle(selector)
+	if text == "" {
+		return 0.0, nil
+	}
+	value, err := parseFloat(text)
+	if err != nil {
+		return 0.0, &ExtractionError{URL: d.url, Selector: selector, Err: err}
+	}
+	return value, nil
+}
+
+// GetTime returns the first element matching selector parsed with format
+// Returns an *ExtractionError if no match is found or the date cannot be parsed
+func (d *Document) GetTime(selector, format string) (*time.Time, error) {
+	text := d.GetTextSingle(selector)
+	if text == "" {
+		return nil, &ExtractionError{URL: d.url, Selector: selector, Err: errors.New("failed to get date text")}
+	}
+	t, err := parseTime(text, format)
+	if err != nil {
+		return nil, &ExtractionError{URL: d.url, Selector: selector, Err: err}
+	}
+	return t, nil
+}
+
+// Unmarshal extracts the document scope into the struct pointed to by v
+// See the package level Unmarshal for the supported struct tags
+func (d *Document) Unmarshal(v any) error {
+	return unmarshalSelection(d.sel, v)
+}
+
+// ScrapeDocument fetches url and parses it into a Document
+func (s *Scraper) ScrapeDocument(url string) (*Document, error) {
+	return s.ScrapeDocumentContext(context.Background(), url)
+}
+
+// ScrapeDocumentContext is like ScrapeDocument but honours ctx cancellation
+func (s *Scraper) ScrapeDocumentContext(ctx context.Context, url string) (*Document, error) {
+	return s.ScrapeDocumentRequest(ctx, &Request{URL: url})
+}
+
+// ScrapeDocumentRequest is like ScrapeDocumentContext for a custom request
+func (s *Scraper) ScrapeDocumentRequest(ctx context.Context, req *Request) (*Document, error) {
+	resp, err := s.FetchRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return NewDocumentFromResponse(resp)
+}
@@ -0,0 +1,195 @@
+package scraper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const testDocumentHTML = `
+<html><body>
+	<h1>Catalog</h1>
+	<div class="product" data-id="1">
+		<h2>Red shoe</h2><span class="price">$1,250.50</span><span class="stock">3</span>
+		<time datetime="2024-01-02">Jan 2</time>
+	</div>
+	<div class="product" data-id="2">
+		<h2>Blue shoe</h2><span class="price">20</span>
+	</div>
+</body></html>`
+
+// TestDocument_Extraction verifies Document methods match the string-based helpers
+func TestDocument_Extraction(t *testing.T) {
+	doc, err := NewDocument(testDocumentHTML)
+	if err != nil {
+		t.Fatalf("NewDocument() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		selector string
+	}{
+		{"Element text", "h2"},
+		{"Attribute", "div.product[data-id]"},
+		{"Multiple selectors", "h1 || span.stock"},
+		{"No match", "table"},
+		{"Empty selector", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			texts, _ := GetText(testDocumentHTML, tt.selector)
+			if got := doc.GetText(tt.selector); !equalStrings(got, texts) {
+				t.Errorf("GetText(%q) = %v, want %v", tt.selector, got, texts)
+			}
+
+			single, _ := GetTextSingle(testDocumentHTML, tt.selector)
+			if got := doc.GetTextSingle(tt.selector); got != single {
+				t.Errorf("GetTextSingle(%q) = %q, want %q", tt.selector, got, single)
+			}
+
+			htmls, _ := GetOuterHTML(testDocumentHTML, tt.selector)
+			if got := doc.GetOuterHTML(tt.selector); !equalStrings(got, htmls) {
+				t.Errorf("GetOuterHTML(%q) = %v, want %v", tt.selector, got, htmls)
+			}
+		})
+	}
+
+	if price, err := doc.GetFloat("span.price"); err != nil || price != 1250.5 {
+		t.Errorf("GetFloat() = %v, %v", price, err)
+	}
+	if stock, err := doc.GetInt("span.stock"); err != nil || stock != 3 {
+		t.Errorf("GetInt() = %v, %v", stock, err)
+	}
+	if date, err := doc.GetTime("time[datetime]", "2006-01-02"); err != nil || date.Day() != 2 {
+		t.Errorf("GetTime() = %v, %v", date, err)
+	}
+}
+
+// TestDocument_Find verifies sub-documents are scoped to their element
+func TestDocument_Find(t *testing.T) {
+	doc, err := NewDocument(testDocumentHTML)
+	if err != nil {
+		t.Fatalf("NewDocument() error = %v", err)
+	}
+
+	products := doc.Find("div.product")
+	if len(products) != 2 {
+		t.Fatalf("Expected 2 products, got %d", len(products))
+	}
+
+	expected := []struct {
+		name  string
+		price float64
+	}{
+		{"Red shoe", 1250.5},
+		{"Blue shoe", 20},
+	}
+	for i, product := range products {
+		if got := product.GetTextSingle("h2"); got != expected[i].name {
+			t.Errorf("Product %d name = %q, want %q", i, got, expected[i].name)
+		}
+		if got, _ := product.GetFloat("span.price"); got != expected[i].price {
+			t.Errorf("Product %d price = %v, want %v", i, got, expected[i].price)
+		}
+		if id, _ := product.Attr("data-id"); id == "" {
+			t.Errorf("Product %d has no data-id", i)
+		}
+	}
+
+	// Scoped lookups must not leak into sibling elements
+	if stock := products[1].GetTextSingle("span.stock"); stock != "" {
+		t.Errorf("Expected no stock in second product, got %q", stock)
+	}
+
+	var item struct {
+		Name string `scrape:"h2"`
+		ID   int    `scrape:";attr=data-id"`
+	}
+	if err := products[1].Unmarshal(&item); err != nil || item.Name != "Blue shoe" || item.ID != 2 {
+		t.Errorf("Unmarshal() = %+v, %v", item, err)
+	}
+}
+
+// TestDocument_BaseHref verifies links resolve against <base href> when present
+func TestDocument_BaseHref(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		url      string
+		expected []string
+	}{
+		{
+			"Document URL",
+			`<a href="page2.html">2</a><a href="../up">up</a>`,
+			"https://example.com/list/index.html",
+			[]string{"https://example.com/list/page2.html", "https://example.com/up"},
+		},
+		{
+			"Relative base",
+			`<html><head><base href="/static/"></head><body><a href="page2.html">2</a></body></html>`,
+			"https://example.com/list/index.html",
+			[]string{"https://example.com/static/page2.html"},
+		},
+		{
+			"Absolute base",
+			`<html><head><base href="https://cdn.example.com/v1/"></head><body><a href="x">x</a></body></html>`,
+			"",
+			[]string{"https://cdn.example.com/v1/x"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := NewDocumentFromResponse(&Response{Body: []byte(tt.html), URL: tt.url})
+			if err != nil {
+				t.Fatalf("NewDocumentFromResponse() error = %v", err)
+			}
+			if got := doc.GetURLs("a[href]"); !equalStrings(got, tt.expected) {
+				t.Errorf("GetURLs() = %v, want %v", got, tt.expected)
+			}
+			// Sub-documents keep the base of their document
+			for _, link := range doc.Find("a") {
+				if link.BaseURL() != doc.BaseURL() {
+					t.Errorf("Sub-document base = %q, want %q", link.BaseURL(), doc.BaseURL())
+				}
+			}
+		})
+	}
+}
+
+// TestScrapeDocument verifies a fetched document keeps the final response URL
+func TestScrapeDocument(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/new", http.StatusFound)
+			return
+		}
+		_, _ = w.Write([]byte(`<p class="msg">moved</p>`))
+	}))
+	defer server.Close()
+
+	s := New(Options{MaxRetries: 1})
+	doc, err := s.ScrapeDocument(server.URL + "/old")
+	if err != nil {
+		t.Fatalf("ScrapeDocument() error = %v", err)
+	}
+	if doc.URL() != server.URL+"/new" {
+		t.Errorf("URL() = %q, want %q", doc.URL(), server.URL+"/new")
+	}
+	if doc.GetTextSingle("p.msg") != "moved" {
+		t.Errorf("Unexpected document: %s", doc.HTML())
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,107 @@
+package scraper
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	// ErrDomainNotAllowed is matched by errors.Is for URLs outside Options.AllowedDomains
+	ErrDomainNotAllowed = errors.New("domain not allowed")
+	// ErrMaxRetriesExceeded is matched by errors.Is when a retryable failure persisted
+	// until MaxRetries or RetryPolicy.MaxElapsedTime ran out. The last failure is
+	// wrapped as well, e.g. an *HTTPStatusError
+	ErrMaxRetriesExceeded = errors.New("max retries exceeded")
+	// ErrLoginFailed is returned by Scraper.Login when LoginForm.SuccessSelector
+	// does not match the page after submitting the form
+	ErrLoginFailed = errors.New("login failed")
+)
+
+// bodySnippetSize is the number of body bytes kept in an HTTPStatusError
+const bodySnippetSize = 512
+
+// HTTPStatusError reports a response with an unsuccessful status code
+type HTTPStatusError struct {
+	StatusCode int
+	URL        string
+	// Body holds the start of the response body for diagnostics
+	Body string
+}
+
+// Error implements the error interface
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d %s from %s", e.StatusCode, http.StatusText(e.StatusCode), e.URL)
+}
+
+// RateLimitedError is an HTTPStatusError for a 429 Too Many Requests response
+// errors.As matches it as both *RateLimitedError and *HTTPStatusError
+type RateLimitedError struct {
+	*HTTPStatusError
+	// RetryAfter is the delay requested by the Retry-After header, 0 if absent
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface
+func (e *RateLimitedError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("rate limited by %s, retry after %v", e.URL, e.RetryAfter)
+	}
+	return fmt.Sprintf("rate limited by %s", e.URL)
+}
+
+// Unwrap returns the underlying HTTPStatusError
+func (e *RateLimitedError) Unwrap() error {
+	return e.HTTPStatusError
+}
+
+// ExtractionError reports a value that could not be extracted from a page
+type ExtractionError struct {
+	URL      string
+	Selector string
+	Err      error
+}
+
+// Error implements the error interface
+func (e *ExtractionError) Error() string {
+	if e.URL == "" {
+		return fmt.Sprintf("failed to extract %q: %v", e.Selector, e.Err)
+	}
+	return fmt.Sprintf("failed to extract %q from %s: %v", e.Selector, e.URL, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *ExtractionError) Unwrap() error {
+	return e.Err
+}
+
+// statusError returns the typed error for an unsuccessful response
+func statusError(resp *Response) error {
+	body := resp.Body
+	if len(body) > bodySnippetSize {
+		body = body[:bodySnippetSize]
+		// Do not cut a multi-byte character in half
+		for len(body) > 0 && !utf8.Valid(body) {
+			body = body[:len(body)-1]
+		}
+	}
+
+	url := resp.URL
+	if resp.RequestURL != "" {
+		url = resp.RequestURL
+	}
+	statusErr := &HTTPStatusError{
+		StatusCode: resp.StatusCode,
+		URL:        url,
+		Body:       strings.TrimSpace(string(body)),
+	}
+
+	if resp.StatusCode != http.StatusTooManyRequests {
+		return statusErr
+	}
+	retryAfter, _ := ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	return &RateLimitedError{HTTPStatusError: statusErr, RetryAfter: retryAfter}
+}
@@ -0,0 +1,157 @@
+package scraper
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestFetch_TypedErrors verifies failures can be routed with errors.Is and errors.As
+func TestFetch_TypedErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte("  no such page " + strings.Repeat("x", 1000)))
+		case "/limited":
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case "/unavailable":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	s := New(Options{MaxRetries: 2, RetryPolicy: RetryPolicy{BaseBackoff: time.Millisecond}})
+
+	t.Run("Not found", func(t *testing.T) {
+		_, err := s.Fetch(server.URL + "/missing")
+		var statusErr *HTTPStatusError
+		if !errors.As(err, &statusErr) {
+			t.Fatalf("Expected HTTPStatusError, got %v", err)
+		}
+		if statusErr.StatusCode != http.StatusNotFound || statusErr.URL != server.URL+"/missing" {
+			t.Errorf("Unexpected error fields: %+v", statusErr)
+		}
+		if !strings.HasPrefix(statusErr.Body, "no such page") || len(statusErr.Body) > bodySnippetSize {
+			t.Errorf("Unexpected body snippet of %d bytes", len(statusErr.Body))
+		}
+		if errors.Is(err, ErrMaxRetriesExceeded) {
+			t.Error("Non-retryable status should not match ErrMaxRetriesExceeded")
+		}
+	})
+
+	t.Run("Rate limited", func(t *testing.T) {
+		_, err := s.Fetch(server.URL + "/limited")
+		var limited *RateLimitedError
+		var statusErr *HTTPStatusError
+		if !errors.As(err, &limited) || !errors.As(err, &statusErr) {
+			t.Fatalf("Expected RateLimitedError and HTTPStatusError, got %v", err)
+		}
+		if statusErr.StatusCode != http.StatusTooManyRequests {
+			t.Errorf("StatusCode = %d", statusErr.StatusCode)
+		}
+		if !errors.Is(err, ErrMaxRetriesExceeded) {
+			t.Errorf("Expected ErrMaxRetriesExceeded, got %v", err)
+		}
+	})
+
+	t.Run("Retries exhausted", func(t *testing.T) {
+		_, err := s.Fetch(server.URL + "/unavailable")
+		var statusErr *HTTPStatusError
+		if !errors.Is(err, ErrMaxRetriesExceeded) || !errors.As(err, &statusErr) {
+			t.Fatalf("Expected ErrMaxRetriesExceeded wrapping HTTPStatusError, got %v", err)
+		}
+		if statusErr.StatusCode != http.StatusServiceUnavailable {
+			t.Errorf("StatusCode = %d", statusErr.StatusCode)
+		}
+	})
+}
+
+// TestFetch_DomainNotAllowed verifies AllowedDomains rejects other hosts before fetching
+func TestFetch_DomainNotAllowed(t *testing.T) {
+	hits := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+	}))
+	defer server.Close()
+
+	s := New(Options{MaxRetries: 1, AllowedDomains: []string{"example.com"}})
+	_, err := s.Fetch(server.URL)
+	if !errors.Is(err, ErrDomainNotAllowed) {
+		t.Errorf("Expected ErrDomainNotAllowed, got %v", err)
+	}
+	if hits != 0 {
+		t.Errorf("Expected no request, got %d", hits)
+	}
+
+	s = New(Options{MaxRetries: 1, AllowedDomains: []string{"127.0.0.1"}})
+	if _, err := s.Fetch(server.URL); err != nil {
+		t.Errorf("Fetch() error = %v", err)
+	}
+}
+
+// TestDocument_ExtractionError verifies conversion failures carry the selector and URL
+func TestDocument_ExtractionError(t *testing.T) {
+	doc, err := NewDocumentFromResponse(&Response{
+		URL:  "https://example.com/item",
+		Body: []byte(`<span class="price">free</span>`),
+	})
+	if err != nil {
+		t.Fatalf("NewDocumentFromResponse() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		selector string
+		extract  func(selector string) error
+	}{
+		{"Float", "span.price", func(sel string) error { _, err := doc.GetFloat(sel); return err }},
+		{"Int", "span.price", func(sel string) error { _, err := doc.GetInt(sel); return err }},
+		{"Time", "time.date", func(sel string) error { _, err := doc.GetTime(sel, "2006-01-02"); return err }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var extractionErr *ExtractionError
+			if err := tt.extract(tt.selector); !errors.As(err, &extractionErr) {
+				t.Fatalf("Expected ExtractionError, got %v", err)
+			}
+			if extractionErr.Selector != tt.selector || extractionErr.URL != "https://example.com/item" {
+				t.Errorf("Unexpected error fields: %+v", extractionErr)
+			}
+		})
+	}
+}
+
+// TestExtractionError_StringHelpers verifies the string based helpers report
+// conversion failures as *ExtractionError like the Document methods
+func TestExtractionError_StringHelpers(t *testing.T) {
+	const html = `<span class="price">free</span><time class="date">someday</time>`
+
+	tests := []struct {
+		name     string
+		selector string
+		extract  func(selector string) error
+	}{
+		{"Float", "span.price", func(sel string) error { _, err := GetFloat(html, sel); return err }},
+		{"Int", "span.price", func(sel string) error { _, err := GetInt(html, sel); return err }},
+		{"Time", "time.date", func(sel string) error { _, err := GetTime(html, sel, "2006-01-02"); return err }},
+		{"Missing time", "time.missing", func(sel string) error { _, err := GetTime(html, sel, "2006-01-02"); return err }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var extractionErr *ExtractionError
+			if err := tt.extract(tt.selector); !errors.As(err, &extractionErr) {
+				t.Fatalf("Expected ExtractionError, got %v", err)
+			}
+			if extractionErr.Selector != tt.selector || extractionErr.URL != "" {
+				t.Errorf("Unexpected error fields: %+v", extractionErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,378 @@
+package scraper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+)
+
+// Request describes a single HTTP request issued through a Fetcher
+// Query, Form, JSON and Timeout are applied by the Scraper, so Fetchers only
+// see Method, URL, Header and Body
+type Request struct {
+	// Method is the HTTP method, defaults to POST when a body is set and GET otherwise
+	Method string
+	// URL is the absolute URL to request
+	URL string
+	// Header holds additional request headers, e.g. Accept-Language or API keys
+	Header http.Header
+	// Query holds parameters added to the query of URL
+	Query url.Values
+	// Form is sent as an application/x-www-form-urlencoded body
+	Form url.Values
+	// JSON is encoded as an application/json body
+	JSON any
+	// Body is the raw request body, if any
+	Body []byte
+	// Timeout bounds each attempt of the request, 0 means no limit
+	Timeout time.Duration
+	// Proxy is the URL of the proxy to send the request through, if any
+	// Set by the Scraper from Options.Proxies, custom Fetchers should honour it
+	Proxy string
+	// Jar stores the cookies sent and received, including across redirects
+	// Set by the Scraper from Options.Session, custom Fetchers should honour it
+	Jar http.CookieJar
+}
+
+// Response holds the outcome of a single HTTP request
+type Response struct {
+	// StatusCode is the HTTP status code returned by the server
+	StatusCode int
+	// Header contains the response headers
+	Header http.Header
+	// Body is the response body, transcoded to UTF-8 for text content
+	// Fetchers return it as received
+	Body []byte
+	// URL is the final URL of the response after following redirects
+	URL string
+	// RequestURL is the URL originally requested, before any redirects
+	RequestURL string
+	// ContentType is the media type from the Content-Type header, without parameters
+	ContentType string
+	// FetchedAt is the time the final attempt was started
+	FetchedAt time.Time
+	// Duration is the time taken by the final attempt
+	Duration time.Duration
+	// Attempts is the number of attempts made to obtain this response
+	Attempts int
+	// FromCache reports whether the body was served from the response cache
+	FromCache bool
+	// Proxy is the proxy the response was fetched through, with the password redacted
+	Proxy string
+	// Charset is the charset the body was decoded from, e.g. "shift_jis",
+	// empty for non-text content
+	Charset string
+}
+
+// HTML returns the response body as a string
+func (r *Response) HTML() string {
+	return string(r.Body)
+}
+
+// Fetcher performs HTTP requests on behalf of a Scraper
+// Implementations must return a Response for every HTTP status code and
+// reserve errors for transport failures (DNS, connection, timeouts, cancellation)
+type Fetcher interface {
+	Fetch(ctx context.Context, req *Request) (*Response, error)
+}
+
+// FetcherFunc adapts an ordinary function to the Fetcher interface
+type FetcherFunc func(ctx context.Context, req *Request) (*Response, error)
+
+// Fetch calls f(ctx, req)
+func (f FetcherFunc) Fetch(ctx context.Context, req *Request) (*Response, error) {
+	return f(ctx, req)
+}
+
+// parseContentType returns the media type of a Content-Type header value
+func parseContentType(header string) string {
+	if header == "" {
+		return ""
+	}
+	mediaType, _, err := mime.ParseMediaType(header)
+	if err != nil {
+		return ""
+	}
+	return mediaType
+}
+
+func (r *Request) method() string {
+	if r.Method == "" {
+		return http.MethodGet
+	}
+	return r.Method
+}
+
+// prepare returns a copy of r with Query merged into the URL and Form or JSON
+// encoded into the body
+func (r *Request) prepare() (*Request, error) {
+	req := *r
+	req.Header = r.Header.Clone()
+	if req.Header == nil {
+		req.Header = http.Header{}
+	}
+
+	if len(r.Query) > 0 {
+		u, err := url.Parse(r.URL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid URL %q: %w", r.URL, err)
+		}
+		query := u.Query()
+		for key, values := range r.Query {
+			query[key] = values
+		}
+		u.RawQuery = query.Encode()
+		req.URL = u.String()
+	}
+
+	switch {
+	case r.Form != nil && r.JSON != nil:
+		return nil, errors.New("only one of Form and JSON may be set")
+	case r.Form != nil:
+		req.Body = []byte(r.Form.Encode())
+		setDefaultHeader(req.Header, "Content-Type", "application/x-www-form-urlencoded")
+	case r.JSON != nil:
+		body, err := json.Marshal(r.JSON)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode JSON body: %w", err)
+		}
+		req.Body = body
+		setDefaultHeader(req.Header, "Content-Type", "application/json")
+	}
+	req.Query, req.Form, req.JSON = nil, nil, nil
+
+	if req.Method == "" && req.Body != nil {
+		req.Method = http.MethodPost
+	}
+	req.Method = req.method()
+	return &req, nil
+}
+
+// setDefaultHeader sets key to value unless header already has it
+func setDefaultHeader(header http.Header, key, value string) {
+	if header.Get(key) == "" {
+		header.Set(key, value)
+	}
+}
+
+// collyFetcher is the default Fetcher backed by a colly collector
+type collyFetcher struct {
+	options Options
+}
+
+// NewCollyFetcher returns the default colly based Fetcher configured from opts
+// UserAgent, AllowedDomains, MaxDepth and Async are honoured
+func NewCollyFetcher(opts Options) Fetcher {
+	return &collyFetcher{options: opts}
+}
+
+// createCollector creates a new colly collector with the fetcher's options
+func (f *collyFetcher) createCollector(additionalOpts ...colly.CollectorOption) *colly.Collector {
+	collyOpts := []colly.CollectorOption{
+		colly.UserAgent(f.options.UserAgent),
+		colly.AllowURLRevisit(),
+		colly.ParseHTTPErrorResponse(),
+	}
+
+	if len(f.options.AllowedDomains) > 0 {
+		collyOpts = append(collyOpts, colly.AllowedDomains(f.options.AllowedDomains...))
+	}
+
+	if f.options.MaxDepth > 0 {
+		collyOpts = append(collyOpts, colly.MaxDepth(f.options.MaxDepth))
+	}
+
+	// Add any additional options passed to this method
+	collyOpts = append(collyOpts, additionalOpts...)
+
+	c := colly.NewCollector(collyOpts...)
+
+	if f.options.Async {
+		c.Async = true
+	}
+
+	return c
+}
+
+// Fetch performs req with a fresh colly collector
+func (f *collyFetcher) Fetch(ctx context.Context, req *Request) (*Response, error) {
+	c := f.createCollector(colly.StdlibContext(ctx))
+	if req.Proxy != "" {
+		if err := c.SetProxy(req.Proxy); err != nil {
+			return nil, err
+		}
+	}
+	if req.Jar != nil {
+		c.SetCookieJar(req.Jar)
+	}
+
+	var resp *Response
+	var fetchErr error
+
+	// Colly transcodes bodies declaring a charset, hide the declaration until
+	// the body is read so the Scraper decodes the raw body like for any Fetcher
+	var contentType string
+	c.OnResponseHeaders(func(r *colly.Response) {
+		contentType = r.Headers.Get("Content-Type")
+		if strings.Contains(strings.ToLower(contentType), "charset") {
+			mediaType, _, _ := strings.Cut(contentType, ";")
+			r.Headers.Set("Content-Type", strings.TrimSpace(mediaType))
+		}
+	})
+
+	c.OnResponse(func(r *colly.Response) {
+		resp = &Response{
+			StatusCode: r.StatusCode,
+			Header:     http.Header{},
+			Body:       r.Body,
+			URL:        r.Request.URL.String(),
+		}
+		if r.Headers != nil {
+			resp.Header = r.Headers.Clone()
+		}
+		if contentType != "" {
+			resp.Header.Set("Content-Type", contentType)
+		}
+	})
+
+	c.OnError(func(r *colly.Response, err error) {
+		fetchErr = err
+	})
+
+	var body io.Reader
+	if req.Body != nil {
+		body = bytes.NewReader(req.Body)
+	}
+
+	var header http.Header
+	if req.Header != nil {
+		header = req.Header.Clone()
+	}
+
+	err := c.Request(req.method(), req.URL, body, nil, header)
+	c.Wait()
+
+	if err == nil {
+		err = fetchErr
+	}
+	if errors.Is(err, colly.ErrForbiddenDomain) {
+		// Redirects may leave the allowed domains
+		return nil, fmt.Errorf("%w: %w", ErrDomainNotAllowed, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// httpFetcher is a Fetcher backed by a standard library http.Client
+type httpFetcher struct {
+	client    *http.Client
+	userAgent string
+	// proxied caches a client per proxy URL
+	proxied sync.Map
+}
+
+// NewHTTPFetcher returns a Fetcher that performs requests with client
+// If client is nil, http.DefaultClient is used
+func NewHTTPFetcher(client *http.Client, userAgent string) Fetcher {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &httpFetcher{client: client, userAgent: userAgent}
+}
+
+// clientFor returns the client sending requests through proxy
+// The client shares the settings of the underlying client with a copy of its
+// transport, which must be an *http.Transport
+func (f *httpFetcher) clientFor(proxy string) (*http.Client, error) {
+	if proxy == "" {
+		return f.client, nil
+	}
+	if client, ok := f.proxied.Load(proxy); ok {
+		return client.(*http.Client), nil
+	}
+
+	proxyURL, err := url.Parse(proxy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL %q: %w", proxy, err)
+	}
+
+	var transport *http.Transport
+	switch t := f.client.Transport.(type) {
+	case nil:
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		transport = t.Clone()
+	default:
+		return nil, fmt.Errorf("cannot use proxy with transport %T", t)
+	}
+	transport.Proxy = http.ProxyURL(proxyURL)
+
+	client := *f.client
+	client.Transport = transport
+	actual, _ := f.proxied.LoadOrStore(proxy, &client)
+	return actual.(*http.Client), nil
+}
+
+// Fetch performs req with the underlying http.Client
+func (f *httpFetcher) Fetch(ctx context.Context, req *Request) (*Response, error) {
+	client, err := f.clientFor(req.Proxy)
+	if err != nil {
+		return nil, err
+	}
+	if req.Jar != nil {
+		withJar := *client
+		withJar.Jar = req.Jar
+		client = &withJar
+	}
+
+	var body io.Reader
+	if req.Body != nil {
+		body = bytes.NewReader(req.Body)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.method(), req.URL, body)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, values := range req.Header {
+		for _, value := range values {
+			httpReq.Header.Add(key, value)
+		}
+	}
+	if httpReq.Header.Get("User-Agent") == "" && f.userAgent != "" {
+		httpReq.Header.Set("User-Agent", f.userAgent)
+	}
+
+	httpResp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	respBody, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Response{
+		StatusCode: httpResp.StatusCode,
+		Header:     httpResp.Header,
+		Body:       respBody,
+		URL:        httpResp.Request.URL.String(),
+	}, nil
+}
@@ -0,0 +1,198 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// TestOptions_CustomFetcher verifies all scraper methods are routed through Options.Fetcher
+func TestOptions_CustomFetcher(t *testing.T) {
+	pages := map[string]string{
+		"https://example.com/":      `<div class="item">A</div><a class="next" href="/page2">Next</a>`,
+		"https://example.com/page2": `<div class="item">B</div>`,
+	}
+
+	var requested []string
+	fetcher := FetcherFunc(func(ctx context.Context, req *Request) (*Response, error) {
+		requested = append(requested, req.URL)
+		body, ok := pages[req.URL]
+		if !ok {
+			return &Response{StatusCode: http.StatusNotFound, URL: req.URL}, nil
+		}
+		return &Response{StatusCode: http.StatusOK, Body: []byte(body), URL: req.URL}, nil
+	})
+
+	s := New(Options{MaxRetries: 1, Fetcher: fetcher})
+
+	html, err := s.ScrapeHTML("https://example.com/")
+	if err != nil {
+		t.Fatalf("ScrapeHTML() error = %v", err)
+	}
+	if !strings.Contains(html, "A") {
+		t.Errorf("ScrapeHTML() = %q, want page content", html)
+	}
+
+	elements, err := s.ScrapeOuterHTML("https://example.com/page2", "div.item")
+	if err != nil {
+		t.Fatalf("ScrapeOuterHTML() error = %v", err)
+	}
+	if len(elements) != 1 {
+		t.Errorf("ScrapeOuterHTML() returned %d elements, want 1", len(elements))
+	}
+
+	resultsChan, err := s.ScrapePaginated("https://example.com/", "div.item", PaginationConfig{
+		NextPageSelector: "a.next[href]",
+	})
+	if err != nil {
+		t.Fatalf("ScrapePaginated() error = %v", err)
+	}
+	count := 0
+	for result := range resultsChan {
+		if result.Err != nil {
+			t.Errorf("Received error from channel: %v", result.Err)
+			continue
+		}
+		count++
+	}
+	if count != 2 {
+		t.Errorf("ScrapePaginated() returned %d results, want 2", count)
+	}
+
+	if len(requested) != 4 {
+		t.Errorf("Expected 4 requests through the fetcher, got %d: %v", len(requested), requested)
+	}
+}
+
+// TestOptions_CustomFetcherError verifies transport errors from the fetcher are surfaced
+func TestOptions_CustomFetcherError(t *testing.T) {
+	fetchErr := errors.New("connection refused")
+	s := New(Options{
+		MaxRetries: 1,
+		Fetcher: FetcherFunc(func(ctx context.Context, req *Request) (*Response, error) {
+			return nil, fetchErr
+		}),
+	})
+
+	_, err := s.ScrapeHTML("https://example.com/")
+	if !errors.Is(err, fetchErr) {
+		t.Errorf("Expected fetcher error to be wrapped, got: %v", err)
+	}
+}
+
+// TestFetchers verifies the built-in fetchers report status, headers, body and final URL
+func TestFetchers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/redirect":
+			http.Redirect(w, r, "/final", http.StatusFound)
+		case "/final":
+			w.Header().Set("X-Test", r.Header.Get("X-Request"))
+			_, _ = w.Write([]byte("<html>" + r.UserAgent() + "</html>"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte("missing"))
+		}
+	}))
+	defer server.Close()
+
+	fetchers := map[string]Fetcher{
+		"Colly": NewCollyFetcher(Options{UserAgent: "TestBot/1.0"}),
+		"HTTP":  NewHTTPFetcher(nil, "TestBot/1.0"),
+	}
+
+	for name, fetcher := range fetchers {
+		t.Run(name, func(t *testing.T) {
+			resp, err := fetcher.Fetch(context.Background(), &Request{
+				URL:    server.URL + "/redirect",
+				Header: http.Header{"X-Request": []string{"hello"}},
+			})
+			if err != nil {
+				t.Fatalf("Fetch() error = %v", err)
+			}
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("StatusCode = %d, want 200", resp.StatusCode)
+			}
+			if resp.URL != server.URL+"/final" {
+				t.Errorf("URL = %q, want final URL after redirect", resp.URL)
+			}
+			if resp.Header.Get("X-Test") != "hello" {
+				t.Errorf("Expected request header to reach the server, got %q", resp.Header.Get("X-Test"))
+			}
+			if !strings.Contains(string(resp.Body), "TestBot/1.0") {
+				t.Errorf("Expected user agent in body, got %q", resp.Body)
+			}
+
+			resp, err = fetcher.Fetch(context.Background(), &Request{URL: server.URL + "/missing"})
+			if err != nil {
+				t.Fatalf("Fetch() should not error on HTTP status, got: %v", err)
+			}
+			if resp.StatusCode != http.StatusNotFound || string(resp.Body) != "missing" {
+				t.Errorf("Expected 404 response with body, got %d %q", resp.StatusCode, resp.Body)
+			}
+		})
+	}
+}
+
+// TestRequest_Prepare verifies query parameters and bodies are encoded for the Fetcher
+func TestRequest_Prepare(t *testing.T) {
+	tests := []struct {
+		name        string
+		req         Request
+		method      string
+		url         string
+		contentType string
+		body        string
+		wantErr     bool
+	}{
+		{"Plain GET", Request{URL: "https://a.test/s"}, "GET", "https://a.test/s", "", "", false},
+		{
+			"Query",
+			Request{URL: "https://a.test/s?q=old&sort=asc", Query: url.Values{"q": {"go scraper"}}},
+			"GET", "https://a.test/s?q=go+scraper&sort=asc", "", "", false,
+		},
+		{
+			"Form",
+			Request{URL: "https://a.test/s", Form: url.Values{"q": {"a&b"}}},
+			"POST", "https://a.test/s", "application/x-www-form-urlencoded", "q=a%26b", false,
+		},
+		{
+			"JSON",
+			Request{Method: "PUT", URL: "https://a.test/s", JSON: map[string]any{"page": 1}},
+			"PUT", "https://a.test/s", "application/json", `{"page":1}`, false,
+		},
+		{
+			"Content type kept",
+			Request{URL: "https://a.test/s", Header: http.Header{"Content-Type": {"application/vnd.api+json"}}, JSON: []int{1}},
+			"POST", "https://a.test/s", "application/vnd.api+json", `[1]`, false,
+		},
+		{
+			"Form and JSON",
+			Request{URL: "https://a.test/s", Form: url.Values{}, JSON: 1},
+			"", "", "", "", true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := tt.req.prepare()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("prepare() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if req.Method != tt.method || req.URL != tt.url || req.Header.Get("Content-Type") != tt.contentType || string(req.Body) != tt.body {
+				t.Errorf("prepare() = %s %s %q %q, want %s %s %q %q",
+					req.Method, req.URL, req.Header.Get("Content-Type"), req.Body, tt.method, tt.url, tt.contentType, tt.body)
+			}
+			if req.Form != nil || req.JSON != nil || req.Query != nil {
+				t.Error("Expected Form, JSON and Query to be cleared")
+			}
+		})
+	}
+}
@@ -0,0 +1,446 @@
+package scraper
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FrontierState is the progress of a URL in a Frontier
+type FrontierState int
+
+const (
+	// FrontierPending URLs are waiting to be fetched
+	FrontierPending FrontierState = iota
+	// FrontierInFlight URLs have been handed out by Next and not finished yet
+	FrontierInFlight
+	// FrontierDone URLs were scraped successfully
+	FrontierDone
+	// FrontierFailed URLs could not be scraped
+	FrontierFailed
+)
+
+// String returns the name of the state
+func (s FrontierState) String() string {
+	switch s {
+	case FrontierPending:
+		return "pending"
+	case FrontierInFlight:
+		return "in_flight"
+	case FrontierDone:
+		return "done"
+	case FrontierFailed:
+		return "failed"
+	default:
+		return fmt.Sprintf("FrontierState(%d)", int(s))
+	}
+}
+
+// FrontierEntry is the state of a single URL in a Frontier
+type FrontierEntry struct {
+	URL   string        `json:"url"`
+	Depth int           `json:"depth,omitempty"`
+	State FrontierState `json:"state"`
+	// Attempts counts how many times the URL was handed out by Next
+	Attempts int `json:"attempts,omitempty"`
+	// Links are the URLs discovered on the page, recorded by Done
+	Links []string `json:"links,omitempty"`
+	// Err is the message of the last failure
+	Err       string    `json:"error,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Frontier is the queue of URLs of a crawl or paginated scrape
+// URLs are deduplicated by their normalized form
+type Frontier interface {
+	// Add queues url as pending unless it is already known
+	// Reports whether the URL was added
+	Add(url string, depth int) (bool, error)
+	// Next hands out the oldest pending URL, marking it in flight
+	// Returns false if no URL is pending
+	Next() (*FrontierEntry, bool, error)
+	// Get returns the entry of url
+	Get(url string) (*FrontierEntry, bool)
+	// Done marks url as scraped, recording the links found on it
+	Done(url string, links []string) error
+	// Fail marks url as failed
+	Fail(url string, err error) error
+	// Entries returns every known URL in the order it was added
+	Entries() []FrontierEntry
+	// Close releases the resources held by the frontier
+	Close() error
+}
+
+// frontierKey returns the deduplication key of url
+func frontierKey(url string) string {
+	key, err := NormalizeURL(url)
+	if err != nil {
+		return url
+	}
+	// PageBodyField pages share their URL and differ by the page in the fragment
+	if _, fragment, ok := strings.Cut(url, "#"); ok && strings.HasPrefix(fragment, bodyPageFragment) {
+		key += "#" + fragment
+	}
+	return key
+}
+
+// memoryFrontier is an in-memory Frontier
+type memoryFrontier struct {
+	mu      sync.Mutex
+	entries map[string]*FrontierEntry
+	order   []string
+	pending []string
+
+	// persist is called with every changed entry while mu is held
+	persist func(entry *FrontierEntry) error
+}
+
+// NewMemoryFrontier returns a Frontier kept in memory
+// Its progress is lost when the process exits
+func NewMemoryFrontier() Frontier {
+	return newMemoryFrontier()
+}
+
+func newMemoryFrontier() *memoryFrontier {
+	return &memoryFrontier{entries: make(map[string]*FrontierEntry)}
+}
+
+// copyEntry returns a copy of entry safe to hand out to callers
+func copyEntry(entry *FrontierEntry) *FrontierEntry {
+	c := *entry
+	c.Links = append([]string(nil), entry.Links...)
+	return &c
+}
+
+// update applies a change to an entry and persists it
+func (f *memoryFrontier) update(entry *FrontierEntry) error {
+	entry.UpdatedAt = time.Now()
+	if f.persist == nil {
+		return nil
+	}
+	return f.persist(entry)
+}
+
+// Add queues url as pending unless it is already known
+func (f *memoryFrontier) Add(url string, depth int) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := frontierKey(url)
+	if _, ok := f.entries[key]; ok {
+		return false, nil
+	}
+
+	entry := &FrontierEntry{URL: url, Depth: depth, State: FrontierPending}
+	f.entries[key] = entry
+	f.order = append(f.order, key)
+	f.pending = append(f.pending, key)
+	return true, f.update(entry)
+}
+
+// Next hands out the oldest pending URL, marking it in flight
+func (f *memoryFrontier) Next() (*FrontierEntry, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for len(f.pending) > 0 {
+		key := f.pending[0]
+		f.pending = f.pending[1:]
+
+		entry := f.entries[key]
+		if entry.State != FrontierPending {
+			continue
+		}
+		entry.State = FrontierInFlight
+		entry.Attempts++
+		return copyEntry(entry), true, f.update(entry)
+	}
+	return nil, false, nil
+}
+
+// Get returns the entry of url
+func (f *memoryFrontier) Get(url string) (*FrontierEntry, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	entry, ok := f.entries[frontierKey(url)]
+	if !ok {
+		return nil, false
+	}
+	return copyEntry(entry), true
+}
+
+// finish moves a known URL to a final state
+func (f *memoryFrontier) finish(url string, state FrontierState, links []string, err error) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	entry, ok := f.entries[frontierKey(url)]
+	if !ok {
+		return fmt.Errorf("url %s is not in the frontier", url)
+	}
+	entry.State = state
+	entry.Links = append([]string(nil), links...)
+	entry.Err = ""
+	if err != nil {
+		entry.Err = err.Error()
+	}
+	return f.update(entry)
+}
+
+// Done marks url as scraped, recording the links found on it
+func (f *memoryFrontier) Done(url string, links []string) error {
+	return f.finish(url, FrontierDone, links, nil)
+}
+
+// Fail marks url as failed
+func (f *memoryFrontier) Fail(url string, err error) error {
+	return f.finish(url, FrontierFailed, nil, err)
+}
+
+// Entries returns every known URL in the order it was added
+func (f *memoryFrontier) Entries() []FrontierEntry {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	entries := make([]FrontierEntry, 0, len(f.order))
+	for _, key := range f.order {
+		entries = append(entries, *copyEntry(f.entries[key]))
+	}
+	return entries
+}
+
+// Close does nothing for an in-memory frontier
+func (f *memoryFrontier) Close() error {
+	return nil
+}
+
+// fileFrontier is a Frontier persisted to a JSON lines log
+type fileFrontier struct {
+	*memoryFrontier
+	file *os.File
+}
+
+var jobIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// NewFileFrontier returns a Frontier persisted in dir under jobID
+// Opening the same job ID again resumes its progress: URLs that were in flight
+// or failed are pending again and keep their attempt counts, done URLs are skipped
+func NewFileFrontier(dir, jobID string) (Frontier, error) {
+	if !jobIDPattern.MatchString(jobID) {
+		return nil, fmt.Errorf("invalid job ID %q", jobID)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(dir, jobID+".jsonl")
+	f := newMemoryFrontier()
+	if err := loadFrontier(path, f); err != nil {
+		return nil, err
+	}
+	if err := compactFrontier(path, f); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	ff := &fileFrontier{memoryFrontier: f, file: file}
+	f.persist = ff.append
+	return ff, nil
+}
+
+// loadFrontier replays the log at path into f, the last record of a URL wins
+func loadFrontier(path string, f *memoryFrontier) error {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var entry FrontierEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// A torn final line from a crash is ignored
+			continue
+		}
+		key := frontierKey(entry.URL)
+		if _, ok := f.entries[key]; !ok {
+			f.order = append(f.order, key)
+		}
+		f.entries[key] = &entry
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	// Unfinished and failed URLs are retried
+	for _, key := range f.order {
+		entry := f.entries[key]
+		if entry.State == FrontierInFlight || entry.State == FrontierFailed {
+			entry.State = FrontierPending
+		}
+		if entry.State == FrontierPending {
+			f.pending = append(f.pending, key)
+		}
+	}
+	return nil
+}
+
+// compactFrontier rewrites the log at path with one record per URL
+func compactFrontier(path string, f *memoryFrontier) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "frontier-*.tmp")
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(tmp)
+	enc := json.NewEncoder(w)
+	for _, key := range f.order {
+		if err := enc.Encode(f.entries[key]); err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// append writes entry to the log
+func (f *fileFrontier) append(entry *FrontierEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	_, err = f.file.Write(append(data, '\n'))
+	return err
+}
+
+// Close closes the log file
+func (f *fileFrontier) Close() error {
+	return f.file.Close()
+}
+
+// frontierVisit scrapes a URL handed out by a Frontier and returns the links to queue
+// A non-nil error marks the URL failed
+type frontierVisit func(entry *FrontierEntry) ([]string, error)
+
+// frontierOutcome is the result of visiting an entry
+type frontierOutcome struct {
+	entry *FrontierEntry
+	links []string
+	err   error
+}
+
+// drainFrontier hands the pending URLs of f to a pool of workers calling visit
+// until no URL is pending or in flight. Links returned by visit are queued one
+// level deeper. maxPages limits the URLs handed out, 0 means unlimited
+// URLs still in flight when ctx is cancelled stay in flight so a resumed
+// frontier retries them
+func drainFrontier(ctx context.Context, f Frontier, workers, maxPages int, visit frontierVisit) error {
+	tasks := make(chan *FrontierEntry)
+	outcomes := make(chan frontierOutcome)
+	wg := sync.WaitGroup{}
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for entry := range tasks {
+				links, err := visit(entry)
+				outcomes <- frontierOutcome{entry: entry, links: links, err: err}
+			}
+		}()
+	}
+
+	record := func(o frontierOutcome) error {
+		if o.err != nil {
+			return f.Fail(o.entry.URL, o.err)
+		}
+		// Links are queued before the page is done so a crash cannot lose them
+		for _, link := range o.links {
+			if _, err := f.Add(link, o.entry.Depth+1); err != nil {
+				return err
+			}
+		}
+		return f.Done(o.entry.URL, o.links)
+	}
+
+	var next *FrontierEntry
+	var frontierErr error
+	dispatched, inFlight := 0, 0
+loop:
+	for {
+		if next == nil && (maxPages <= 0 || dispatched < maxPages) {
+			entry, ok, err := f.Next()
+			if err != nil {
+				frontierErr = err
+				break
+			}
+			if ok {
+				next = entry
+			}
+		}
+		if next == nil && inFlight == 0 {
+			break
+		}
+
+		// A nil channel disables the dispatch case while nothing is pending
+		var dispatch chan *FrontierEntry
+		if next != nil {
+			dispatch = tasks
+		}
+
+		select {
+		case dispatch <- next:
+			next = nil
+			dispatched++
+			inFlight++
+		case o := <-outcomes:
+			inFlight--
+			if ctx.Err() != nil {
+				break loop
+			}
+			if err := record(o); err != nil {
+				frontierErr = err
+				break loop
+			}
+		case <-ctx.Done():
+			break loop
+		}
+	}
+
+	close(tasks)
+	go func() {
+		wg.Wait()
+		close(outcomes)
+	}()
+	// Outcomes of pages still running are dropped, leaving them in flight
+	for range outcomes {
+	}
+
+	return frontierErr
+}
@@ -0,0 +1,212 @@
+package scraper
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// TestMemoryFrontier verifies deduplication, queue order and state tracking
+func TestMemoryFrontier(t *testing.T) {
+	f := NewMemoryFrontier()
+
+	for _, u := range []string{"https://example.com/a", "https://example.com/b", "HTTPS://EXAMPLE.com/a#top"} {
+		if _, err := f.Add(u, 1); err != nil {
+			t.Fatalf("Add(%q) error = %v", u, err)
+		}
+	}
+	if entries := f.Entries(); len(entries) != 2 {
+		t.Fatalf("Expected 2 entries after dedupe, got %d", len(entries))
+	}
+
+	first, ok, _ := f.Next()
+	if !ok || first.URL != "https://example.com/a" || first.State != FrontierInFlight || first.Attempts != 1 {
+		t.Fatalf("Next() = %+v, %v", first, ok)
+	}
+	second, _, _ := f.Next()
+	if _, ok, _ := f.Next(); ok {
+		t.Error("Expected no pending entries")
+	}
+
+	if err := f.Done(first.URL, []string{"https://example.com/c"}); err != nil {
+		t.Fatalf("Done() error = %v", err)
+	}
+	if err := f.Fail(second.URL, errors.New("boom")); err != nil {
+		t.Fatalf("Fail() error = %v", err)
+	}
+	if err := f.Done("https://example.com/unknown", nil); err == nil {
+		t.Error("Expected error for unknown URL")
+	}
+
+	tests := []struct {
+		url   string
+		state FrontierState
+		err   string
+	}{
+		{"https://example.com/a", FrontierDone, ""},
+		{"https://example.com/b", FrontierFailed, "boom"},
+	}
+	for _, tt := range tests {
+		entry, ok := f.Get(tt.url)
+		if !ok || entry.State != tt.state || entry.Err != tt.err {
+			t.Errorf("Get(%q) = %+v, want state %v", tt.url, entry, tt.state)
+		}
+	}
+}
+
+// TestFileFrontier_Reopen verifies progress survives reopening with the same job ID
+func TestFileFrontier_Reopen(t *testing.T) {
+	dir := t.TempDir()
+
+	f, err := NewFileFrontier(dir, "job-1")
+	if err != nil {
+		t.Fatalf("NewFileFrontier() error = %v", err)
+	}
+	for _, u := range []string{"https://example.com/done", "https://example.com/failed", "https://example.com/running", "https://example.com/pending"} {
+		f.Add(u, 0)
+	}
+	for i := 0; i < 3; i++ {
+		f.Next()
+	}
+	f.Done("https://example.com/done", []string{"https://example.com/pending"})
+	f.Fail("https://example.com/failed", errors.New("status 500"))
+	f.Close()
+
+	// A torn write at the end of the log is ignored
+	file, _ := os.OpenFile(filepath.Join(dir, "job-1.jsonl"), os.O_APPEND|os.O_WRONLY, 0o644)
+	file.WriteString(`{"url":"https://exa`)
+	file.Close()
+
+	f, err = NewFileFrontier(dir, "job-1")
+	if err != nil {
+		t.Fatalf("NewFileFrontier() error = %v", err)
+	}
+	defer f.Close()
+
+	var pending []string
+	for {
+		entry, ok, err := f.Next()
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		if !ok {
+			break
+		}
+		pending = append(pending, entry.URL)
+		if entry.URL == "https://example.com/failed" && entry.Attempts != 2 {
+			t.Errorf("Expected attempts to keep counting, got %d", entry.Attempts)
+		}
+	}
+
+	expected := []string{"https://example.com/failed", "https://example.com/running", "https://example.com/pending"}
+	if !equalStrings(pending, expected) {
+		t.Errorf("Pending after reopen = %v, want %v", pending, expected)
+	}
+	if entry, _ := f.Get("https://example.com/done"); entry.State != FrontierDone || len(entry.Links) != 1 {
+		t.Errorf("Done entry = %+v", entry)
+	}
+
+	if _, err := NewFileFrontier(dir, "../escape"); err == nil {
+		t.Error("Expected error for invalid job ID")
+	}
+}
+
+// TestScrapePaginated_Resume verifies a restarted job skips completed pages
+func TestScrapePaginated_Resume(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   PaginationConfig
+		pages    map[string]string
+		failing  string
+		first    int
+		expected []string
+	}{
+		{
+			"Sequential",
+			PaginationConfig{NextPageSelector: "a.next[href]"},
+			map[string]string{
+				"/":      `<div class="item">1</div><a class="next" href="/page2">Next</a>`,
+				"/page2": `<div class="item">2</div><a class="next" href="/page3">Next</a>`,
+				"/page3": `<div class="item">3</div>`,
+			},
+			"/page2",
+			1,
+			[]string{"/page2", "/page3"},
+		},
+		{
+			"Parallel",
+			PaginationConfig{LastPageSelector: "span.pages", NextPageURLPattern: "/page::page::"},
+			map[string]string{
+				"/":      `<div class="item">1</div><span class="pages">3</span>`,
+				"/page2": `<div class="item">2</div>`,
+				"/page3": `<div class="item">3</div>`,
+			},
+			"/page3",
+			2,
+			[]string{"/page3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			failing := true
+			var hits []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				defer mu.Unlock()
+				hits = append(hits, r.URL.Path)
+				if failing && r.URL.Path == tt.failing {
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				_, _ = w.Write([]byte(tt.pages[r.URL.Path]))
+			}))
+			defer server.Close()
+
+			dir := t.TempDir()
+			run := func() int {
+				frontier, err := NewFileFrontier(dir, "resume")
+				if err != nil {
+					t.Fatalf("NewFileFrontier() error = %v", err)
+				}
+				defer frontier.Close()
+
+				config := tt.config
+				config.Frontier = frontier
+				s := New(Options{MaxRetries: 1})
+				resultsChan, err := s.ScrapePaginated(server.URL+"/", "div.item", config)
+				if err != nil {
+					t.Fatalf("ScrapePaginated() error = %v", err)
+				}
+				items := 0
+				for result := range resultsChan {
+					if result.Err == nil {
+						items++
+					}
+				}
+				return items
+			}
+
+			if items := run(); items != tt.first {
+				t.Fatalf("Expected %d items in first run, got %d", tt.first, items)
+			}
+
+			mu.Lock()
+			failing = false
+			hits = nil
+			mu.Unlock()
+
+			if items := run(); items != len(tt.expected) {
+				t.Errorf("Expected %d items after resume, got %d", len(tt.expected), items)
+			}
+			if !equalStrings(hits, tt.expected) {
+				t.Errorf("Resumed run fetched %v, want %v", hits, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,760 @@
+package scraper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"gopkg.in/yaml.v3"
+)
+
+// Field types supported by JobField.Type
+const (
+	FieldText  = "text"
+	FieldInt   = "int"
+	FieldFloat = "float"
+	FieldTime  = "time"
+	FieldHTML  = "html"
+	FieldAttr  = "attr"
+)
+
+// httpMethodPattern matches the request methods a job may use
+var httpMethodPattern = regexp.MustCompile(`^[A-Za-z]+$`)
+
+// Duration is a time.Duration written as a string like "500ms" or "2s" in job files
+type Duration time.Duration
+
+// UnmarshalText parses a duration string
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// MarshalText formats the duration as a string
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// Job is a declarative scrape definition loaded from YAML or JSON
+type Job struct {
+	// Name identifies the job in logs and errors
+	Name string `json:"name" yaml:"name"`
+	// URL is the start URL of the scrape
+	URL string `json:"url" yaml:"url"`
+	// Options configures the scraper running the job
+	Options JobOptions `json:"options" yaml:"options"`
+	// Request customises the request sent for every page, e.g. a POST search form
+	Request JobRequest `json:"request" yaml:"request"`
+	// Pagination configures how the job moves between pages
+	Pagination JobPagination `json:"pagination" yaml:"pagination"`
+	// ItemSelector is the CSS selector matching each item on a page, or the
+	// JSON path of the items of JSON pages such as "data.items"
+	ItemSelector string `json:"item_selector" yaml:"item_selector"`
+	// Fields maps output names to their extraction rules
+	Fields map[string]JobField `json:"fields" yaml:"fields"`
+}
+
+// JobOptions mirrors the data fields of Options
+type JobOptions struct {
+	UserAgent           string       `json:"user_agent" yaml:"user_agent"`
+	AllowedDomains      []string     `json:"allowed_domains" yaml:"allowed_domains"`
+	MaxDepth            int          `json:"max_depth" yaml:"max_depth"`
+	Async               bool         `json:"async" yaml:"async"`
+	MaxParallelRequests int          `json:"max_parallel_requests" yaml:"max_parallel_requests"`
+	MaxRetries          int          `json:"max_retries" yaml:"max_retries"`
+	RetryPolicy         JobRetry     `json:"retry" yaml:"retry"`
+	RateLimit           JobRateLimit `json:"rate_limit" yaml:"rate_limit"`
+	CacheDir            string       `json:"cache_dir" yaml:"cache_dir"`
+	CacheTTL            Duration     `json:"cache_ttl" yaml:"cache_ttl"`
+	RespectRobots       bool         `json:"respect_robots" yaml:"respect_robots"`
+	Proxies             []string     `json:"proxies" yaml:"proxies"`
+	// ProxyRotation is round-robin, random or sticky
+	ProxyRotation   string             `json:"proxy_rotation" yaml:"proxy_rotation"`
+	ProxyQuarantine JobProxyQuarantine `json:"proxy_quarantine" yaml:"proxy_quarantine"`
+	// Charset forces the charset pages are decoded from, e.g. shift_jis
+	Charset string `json:"charset" yaml:"charset"`
+	// StateDir persists the job progress under the job name so a restarted
+	// job skips pages it already completed
+	StateDir string `json:"state_dir" yaml:"state_dir"`
+}
+
+// JobRetry mirrors the data fields of RetryPolicy
+type JobRetry struct {
+	RetryableStatusCodes []int    `json:"retryable_status_codes" yaml:"retryable_status_codes"`
+	BaseBackoff          Duration `json:"base_backoff" yaml:"base_backoff"`
+	MaxBackoff           Duration `json:"max_backoff" yaml:"max_backoff"`
+	// Jitter is additive, full, equal or none
+	Jitter           string   `json:"jitter" yaml:"jitter"`
+	MaxElapsedTime   Duration `json:"max_elapsed_time" yaml:"max_elapsed_time"`
+	IgnoreRetryAfter bool     `json:"ignore_retry_after" yaml:"ignore_retry_after"`
+	// NonIdempotent also retries POST and PATCH requests
+	NonIdempotent bool `json:"non_idempotent" yaml:"non_idempotent"`
+}
+
+// JobRateLimit mirrors RateLimit
+type JobRateLimit struct {
+	RequestsPerSecond float64  `json:"requests_per_second" yaml:"requests_per_second"`
+	Burst             int      `json:"burst" yaml:"burst"`
+	Delay             Duration `json:"delay" yaml:"delay"`
+	RandomDelay       Duration `json:"random_delay" yaml:"random_delay"`
+}
+
+// JobProxyQuarantine mirrors ProxyQuarantine
+type JobProxyQuarantine struct {
+	Failures int      `json:"failures" yaml:"failures"`
+	Duration Duration `json:"duration" yaml:"duration"`
+}
+
+// JobRequest mirrors the data fields of Request
+type JobRequest struct {
+	// Method defaults to POST when a form or JSON body is set and GET otherwise
+	Method  string            `json:"method" yaml:"method"`
+	Headers map[string]string `json:"headers" yaml:"headers"`
+	Query   map[string]string `json:"query" yaml:"query"`
+	Form    map[string]string `json:"form" yaml:"form"`
+	JSON    any               `json:"json" yaml:"json"`
+}
+
+// JobPagination mirrors PaginationConfig
+type JobPagination struct {
+	NextPageSelector   string `json:"next_page_selector" yaml:"next_page_selector"`
+	LastPageSelector   string `json:"last_page_selector" yaml:"last_page_selector"`
+	NextPageURLPattern string `json:"next_page_url_pattern" yaml:"next_page_url_pattern"`
+	Ordered            bool   `json:"ordered" yaml:"ordered"`
+	MaxPages           int    `json:"max_pages" yaml:"max_pages"`
+	OffsetParam        string `json:"offset_param" yaml:"offset_param"`
+	LimitParam         string `json:"limit_param" yaml:"limit_param"`
+	Limit              int    `json:"limit" yaml:"limit"`
+	CursorPath         string `json:"cursor_path" yaml:"cursor_path"`
+	CursorParam        string `json:"cursor_param" yaml:"cursor_param"`
+	FollowLinkHeader   bool   `json:"follow_link_header" yaml:"follow_link_header"`
+	// PageBodyField sets the page number in the request form or JSON body
+	PageBodyField string `json:"page_body_field" yaml:"page_body_field"`
+}
+
+// JobField describes how a single named value is extracted from an item
+type JobField struct {
+	// Selector is the CSS selector relative to the item, empty for the item itself
+	// For JSON items it is a JSON path such as "price.amount"
+	Selector string `json:"selector" yaml:"selector"`
+	// Type is one of text, int, float, time, html or attr, defaults to text
+	Type string `json:"type" yaml:"type"`
+	// Attr is the attribute to read, required when Type is attr
+	Attr string `json:"attr" yaml:"attr"`
+	// Format is the time layout (or "ago"), required when Type is time
+	Format string `json:"format" yaml:"format"`
+	// Multiple collects every match into a list instead of the first one
+	Multiple bool `json:"multiple" yaml:"multiple"`
+	// Process lists post-processing steps applied to the raw text before conversion:
+	// trim, lower, upper, squash, absolute_url, regex:<pattern>, replace:<old>=><new>
+	Process []string `json:"process" yaml:"process"`
+}
+
+// JobFieldError reports an invalid value in a job definition
+type JobFieldError struct {
+	// Field is the dotted path of the offending field, e.g. "fields.price.type"
+	Field string
+	// Message describes the problem
+	Message string
+}
+
+// Error implements the error interface
+func (e *JobFieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// LoadJob reads a job definition from a .yaml, .yml or .json file and validates it
+func LoadJob(path string) (*Job, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	format := "yaml"
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		format = "json"
+	}
+
+	job, err := ParseJob(data, format)
+	if err != nil {
+		return nil, fmt.Errorf("job %s: %w", path, err)
+	}
+	return job, nil
+}
+
+// ParseJob decodes a job definition in the given format ("yaml" or "json") and validates it
+// Unknown keys are rejected
+func ParseJob(data []byte, format string) (*Job, error) {
+	var job Job
+
+	switch format {
+	case "json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&job); err != nil {
+			return nil, err
+		}
+	case "yaml", "yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&job); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported job format %q", format)
+	}
+
+	if err := job.Validate(); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Validate checks the job definition and returns every problem found,
+// each as a *JobFieldError naming the offending field
+func (j *Job) Validate() error {
+	var errs []error
+	fail := func(field, format string, args ...any) {
+		errs = append(errs, &JobFieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if j.URL == "" {
+		fail("url", "is required")
+	} else if !strings.HasPrefix(j.URL, "http://") && !strings.HasPrefix(j.URL, "https://") {
+		fail("url", "must be an absolute http(s) URL, got %q", j.URL)
+	}
+
+	if j.ItemSelector == "" {
+		fail("item_selector", "is required")
+	}
+
+	if j.Request.Method != "" && !httpMethodPattern.MatchString(j.Request.Method) {
+		fail("request.method", "must be an HTTP method, got %q", j.Request.Method)
+	}
+	if j.Request.Form != nil && j.Request.JSON != nil {
+		fail("request.json", "cannot be combined with form")
+	}
+
+	if j.Pagination.LastPageSelector != "" && j.Pagination.NextPageURLPattern == "" && j.Pagination.PageBodyField == "" {
+		fail("pagination.next_page_url_pattern", "is required when last_page_selector is set without page_body_field")
+	}
+	if j.Pagination.PageBodyField != "" && j.Request.Form == nil && j.Request.JSON == nil {
+		fail("pagination.page_body_field", "requires a request form or json body")
+	}
+	if j.Pagination.NextPageURLPattern != "" && !strings.Contains(j.Pagination.NextPageURLPattern, "::page::") {
+		fail("pagination.next_page_url_pattern", "must contain the ::page:: placeholder")
+	}
+	// Pagination modes are exclusive, the first one set wins the error message
+	var modes []string
+	for _, mode := range []struct {
+		name string
+		set  bool
+	}{
+		{"next_page_selector", j.Pagination.NextPageSelector != ""},
+		{"next_page_url_pattern", j.Pagination.NextPageURLPattern != ""},
+		{"page_body_field", j.Pagination.PageBodyField != ""},
+		{"offset_param", j.Pagination.OffsetParam != ""},
+		{"cursor_path", j.Pagination.CursorPath != ""},
+		{"follow_link_header", j.Pagination.FollowLinkHeader},
+	} {
+		if mode.set {
+			modes = append(modes, mode.name)
+		}
+	}
+	if len(modes) > 1 {
+		fail("pagination."+modes[1], "cannot be combined with %s", modes[0])
+	}
+	if j.Pagination.MaxPages < 0 {
+		fail("pagination.max_pages", "must not be negative")
+	}
+	if j.Pagination.OffsetParam == "" && (j.Pagination.LimitParam != "" || j.Pagination.Limit != 0) {
+		fail("pagination.offset_param", "is required when limit_param or limit is set")
+	}
+	if j.Pagination.Limit < 0 {
+		fail("pagination.limit", "must not be negative")
+	}
+	if j.Pagination.CursorParam != "" && j.Pagination.CursorPath == "" {
+		fail("pagination.cursor_path", "is required when cursor_param is set")
+	}
+
+	if j.Options.MaxRetries < 0 {
+		fail("options.max_retries", "must not be negative")
+	}
+	for i, code := range j.Options.RetryPolicy.RetryableStatusCodes {
+		if code < 100 || code > 599 {
+			fail(fmt.Sprintf("options.retry.retryable_status_codes[%d]", i), "must be an HTTP status code, got %d", code)
+		}
+	}
+	if j.Options.RetryPolicy.BaseBackoff < 0 {
+		fail("options.retry.base_backoff", "must not be negative")
+	}
+	if j.Options.RetryPolicy.MaxBackoff < 0 {
+		fail("options.retry.max_backoff", "must not be negative")
+	}
+	if j.Options.RetryPolicy.MaxElapsedTime < 0 {
+		fail("options.retry.max_elapsed_time", "must not be negative")
+	}
+	if _, err := ParseJitterStrategy(j.Options.RetryPolicy.Jitter); err != nil {
+		fail("options.retry.jitter", "%v", err)
+	}
+	if j.Options.MaxParallelRequests < 0 {
+		fail("options.max_parallel_requests", "must not be negative")
+	}
+	if j.Options.RateLimit.RequestsPerSecond < 0 {
+		fail("options.rate_limit.requests_per_second", "must not be negative")
+	}
+
+	for i, proxy := range j.Options.Proxies {
+		if u, err := url.Parse(proxy); err != nil || u.Scheme == "" || u.Host == "" {
+			fail(fmt.Sprintf("options.proxies[%d]", i), "must be an absolute proxy URL, got %q", proxy)
+		}
+	}
+	if _, err := ParseProxyRotation(j.Options.ProxyRotation); err != nil {
+		fail("options.proxy_rotation", "%v", err)
+	}
+	if j.Options.Charset != "" {
+		if _, _, err := lookupCharset(j.Options.Charset); err != nil {
+			fail("options.charset", "%v", err)
+		}
+	}
+
+	if j.Options.StateDir != "" && !jobIDPattern.MatchString(j.Name) {
+		fail("name", "must only contain letters, digits, '.', '_' and '-' when options.state_dir is set")
+	}
+
+	if len(j.Fields) == 0 {
+		fail("fields", "at least one field is required")
+	}
+
+	for _, name := range sortedFieldNames(j.Fields) {
+		field := j.Fields[name]
+		path := "fields." + name
+
+		switch field.Type {
+		case "", FieldText, FieldInt, FieldFloat, FieldHTML:
+		case FieldTime:
+			if field.Format == "" {
+				fail(path+".format", "is required for time fields")
+			}
+		case FieldAttr:
+			if field.Attr == "" && GetAttrName(field.Selector) == "" {
+				fail(path+".attr", "is required for attr fields")
+			}
+		default:
+			fail(path+".type", "unknown type %q, expected one of text, int, float, time, html, attr", field.Type)
+		}
+
+		for i, step := range field.Process {
+			if _, err := compileProcessStep(step); err != nil {
+				fail(fmt.Sprintf("%s.process[%d]", path, i), "%v", err)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// sortedFieldNames returns the field names in a stable order
+func sortedFieldNames(fields map[string]JobField) []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// processFunc transforms extracted text
+type processFunc func(text, baseURL string) string
+
+// compileProcessStep parses a post-processing step
+func compileProcessStep(step string) (processFunc, error) {
+	name, arg, _ := strings.Cut(step, ":")
+	switch name {
+	case "trim":
+		return func(text, _ string) string { return strings.TrimSpace(text) }, nil
+	case "lower":
+		return func(text, _ string) string { return strings.ToLower(text) }, nil
+	case "upper":
+		return func(text, _ string) string { return strings.ToUpper(text) }, nil
+	case "squash":
+		return func(text, _ string) string { return strings.Join(strings.Fields(text), " ") }, nil
+	case "absolute_url":
+		return func(text, baseURL string) string { return GetFullURL(baseURL, text) }, nil
+	case "regex":
+		re, err := regexp.Compile(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex: %w", err)
+		}
+		// Keep the first capture group if any, otherwise the whole match
+		return func(text, _ string) string {
+			matches := re.FindStringSubmatch(text)
+			switch {
+			case len(matches) == 0:
+				return ""
+			case len(matches) > 1:
+				return matches[1]
+			default:
+				return matches[0]
+			}
+		}, nil
+	case "replace":
+		old, replacement, ok := strings.Cut(arg, "=>")
+		if !ok {
+			return nil, fmt.Errorf("replace step must look like replace:<old>=><new>")
+		}
+		return func(text, _ string) string { return strings.ReplaceAll(text, old, replacement) }, nil
+	default:
+		return nil, fmt.Errorf("unknown process step %q", name)
+	}
+}
+
+// ScraperOptions converts the job options into scraper Options
+func (j *Job) ScraperOptions() (Options, error) {
+	opts := Options{
+		UserAgent:           j.Options.UserAgent,
+		AllowedDomains:      j.Options.AllowedDomains,
+		MaxDepth:            j.Options.MaxDepth,
+		Async:               j.Options.Async,
+		MaxParallelRequests: j.Options.MaxParallelRequests,
+		MaxRetries:          j.Options.MaxRetries,
+		RetryPolicy: RetryPolicy{
+			RetryableStatusCodes: j.Options.RetryPolicy.RetryableStatusCodes,
+			BaseBackoff:          time.Duration(j.Options.RetryPolicy.BaseBackoff),
+			MaxBackoff:           time.Duration(j.Options.RetryPolicy.MaxBackoff),
+			MaxElapsedTime:       time.Duration(j.Options.RetryPolicy.MaxElapsedTime),
+			IgnoreRetryAfter:     j.Options.RetryPolicy.IgnoreRetryAfter,
+			RetryNonIdempotent:   j.Options.RetryPolicy.NonIdempotent,
+		},
+		RateLimit: RateLimit{
+			RequestsPerSecond: j.Options.RateLimit.RequestsPerSecond,
+			Burst:             j.Options.RateLimit.Burst,
+			Delay:             time.Duration(j.Options.RateLimit.Delay),
+			RandomDelay:       time.Duration(j.Options.RateLimit.RandomDelay),
+		},
+		CacheTTL:      time.Duration(j.Options.CacheTTL),
+		RespectRobots: j.Options.RespectRobots,
+		Proxies:       j.Options.Proxies,
+		ProxyQuarantine: ProxyQuarantine{
+			Failures: j.Options.ProxyQuarantine.Failures,
+			Duration: time.Duration(j.Options.ProxyQuarantine.Duration),
+		},
+		Charset: j.Options.Charset,
+	}
+
+	jitter, err := ParseJitterStrategy(j.Options.RetryPolicy.Jitter)
+	if err != nil {
+		return opts, err
+	}
+	opts.RetryPolicy.Jitter = jitter
+
+	rotation, err := ParseProxyRotation(j.Options.ProxyRotation)
+	if err != nil {
+		return opts, err
+	}
+	opts.ProxyRotation = rotation
+
+	if j.Options.CacheDir != "" {
+		cache, err := NewDiskCache(j.Options.CacheDir)
+		if err != nil {
+			return opts, err
+		}
+		opts.Cache = cache
+	}
+
+	return opts, nil
+}
+
+// PaginationConfig converts the job pagination into a PaginationConfig
+func (j *Job) PaginationConfig() PaginationConfig {
+	return PaginationConfig{
+		NextPageSelector:   j.Pagination.NextPageSelector,
+		LastPageSelector:   j.Pagination.LastPageSelector,
+		NextPageURLPattern: j.Pagination.NextPageURLPattern,
+		Ordered:            j.Pagination.Ordered,
+		MaxPages:           j.Pagination.MaxPages,
+		OffsetParam:        j.Pagination.OffsetParam,
+		LimitParam:         j.Pagination.LimitParam,
+		Limit:              j.Pagination.Limit,
+		CursorPath:         j.Pagination.CursorPath,
+		CursorParam:        j.Pagination.CursorParam,
+		FollowLinkHeader:   j.Pagination.FollowLinkHeader,
+		PageBodyField:      j.Pagination.PageBodyField,
+	}
+}
+
+// HTTPRequest returns the request sent for the first page of the job
+func (j *Job) HTTPRequest() *Request {
+	req := &Request{URL: j.URL, Method: strings.ToUpper(j.Request.Method), JSON: j.Request.JSON}
+	if len(j.Request.Headers) > 0 {
+		req.Header = make(http.Header, len(j.Request.Headers))
+		for name, value := range j.Request.Headers {
+			req.Header.Set(name, value)
+		}
+	}
+	req.Query = jobValues(j.Request.Query)
+	req.Form = jobValues(j.Request.Form)
+	return req
+}
+
+// jobValues converts a job parameter map to url.Values, nil stays nil
+func jobValues(params map[string]string) url.Values {
+	if params == nil {
+		return nil
+	}
+	values := make(url.Values, len(params))
+	for name, value := range params {
+		values.Set(name, value)
+	}
+	return values
+}
+
+// JobRecord is a single item extracted by a job
+type JobRecord struct {
+	// Data maps field names to extracted values
+	Data map[string]any
+	// Err is set if the item or its page could not be scraped
+	Err error
+}
+
+// JSON encodes the record data as a JSON object
+func (r JobRecord) JSON() ([]byte, error) {
+	return json.Marshal(r.Data)
+}
+
+// compiledField is a JobField with its post-processing steps ready to run
+type compiledField struct {
+	name    string
+	field   JobField
+	process []processFunc
+}
+
+// RunJob executes job through ScrapePaginated with a scraper built from its options
+// Each item is emitted as a JobRecord on the returned channel
+func RunJob(ctx context.Context, job *Job) (<-chan JobRecord, error) {
+	opts, err := job.ScraperOptions()
+	if err != nil {
+		return nil, err
+	}
+	return New(opts).RunJob(ctx, job)
+}
+
+// RunJob executes job with this scraper, ignoring the job's own options
+func (s *Scraper) RunJob(ctx context.Context, job *Job) (<-chan JobRecord, error) {
+	if err := job.Validate(); err != nil {
+		return nil, err
+	}
+
+	fields := make([]compiledField, 0, len(job.Fields))
+	for _, name := range sortedFieldNames(job.Fields) {
+		cf := compiledField{name: name, field: job.Fields[name]}
+		for _, step := range cf.field.Process {
+			fn, _ := compileProcessStep(step)
+			cf.process = append(cf.process, fn)
+		}
+		fields = append(fields, cf)
+	}
+
+	config := job.PaginationConfig()
+	if job.Options.StateDir != "" {
+		frontier, err := NewFileFrontier(job.Options.StateDir, job.Name)
+		if err != nil {
+			return nil, err
+		}
+		config.Frontier = frontier
+	}
+
+	results, err := s.ScrapePaginatedRequest(ctx, job.HTTPRequest(), job.ItemSelector, config)
+	if err != nil {
+		if config.Frontier != nil {
+			config.Frontier.Close()
+		}
+		return nil, err
+	}
+
+	records := make(chan JobRecord)
+	go func() {
+		defer close(records)
+		if config.Frontier != nil {
+			defer config.Frontier.Close()
+		}
+		for result := range results {
+			record := JobRecord{Err: result.Err}
+			if result.Err == nil {
+				if result.Item != nil {
+					record.Data, record.Err = extractRecord(result.Item, fields)
+				} else {
+					// Items of JSON pages are emitted as JSON
+					record.Data, record.Err = extractJSONRecord(result.Data, result.URL, fields)
+				}
+			}
+
+			select {
+			case records <- record:
+			case <-ctx.Done():
+				// Drain so the paginator can shut down
+				for range results {
+				}
+				return
+			}
+		}
+	}()
+
+	return records, nil
+}
+
+// extractRecord applies every field to a single item
+// Relative links resolve against the base URL of the page the item came from
+func extractRecord(item *Document, fields []compiledField) (map[string]any, error) {
+	data := make(map[string]any, len(fields))
+	for _, cf := range fields {
+		value, err := cf.extract(item.Selection(), item.BaseURL())
+		if err != nil {
+			return data, &ExtractionError{URL: item.URL(), Selector: cf.field.Selector, Err: fmt.Errorf("field %s: %w", cf.name, err)}
+		}
+		data[cf.name] = value
+	}
+	return data, nil
+}
+
+// extractJSONRecord applies every field to a single JSON item, reading each
+// field selector as a JSON path. Relative links resolve against pageURL
+func extractJSONRecord(itemJSON, pageURL string, fields []compiledField) (map[string]any, error) {
+	item, err := decodeJSON([]byte(itemJSON))
+	if err != nil {
+		return nil, &ExtractionError{URL: pageURL, Err: err}
+	}
+
+	data := make(map[string]any, len(fields))
+	for _, cf := range fields {
+		value, err := cf.extractJSON(item, pageURL)
+		if err != nil {
+			return data, &ExtractionError{URL: pageURL, Selector: cf.field.Selector, Err: fmt.Errorf("field %s: %w", cf.name, err)}
+		}
+		data[cf.name] = value
+	}
+	return data, nil
+}
+
+// extractJSON returns the value of the field within the JSON item
+// html fields keep the JSON encoding of the value, other types read its text
+func (cf compiledField) extractJSON(item any, baseURL string) (any, error) {
+	text := func(v any) (string, error) {
+		if cf.field.Type == FieldHTML {
+			data, err := json.Marshal(v)
+			return string(data), err
+		}
+		if b, ok := v.(bool); ok {
+			return strconv.FormatBool(b), nil
+		}
+		return jsonString(v), nil
+	}
+
+	matches := jsonItems(item, cf.field.Selector)
+	if !cf.field.Multiple {
+		value, ok := jsonPath(item, cf.field.Selector)
+		if !ok || value == nil {
+			return nil, nil
+		}
+		t, err := text(value)
+		if err != nil {
+			return nil, err
+		}
+		return cf.convert(t, baseURL)
+	}
+
+	values := make([]any, 0, len(matches))
+	for _, match := range matches {
+		t, err := text(match)
+		if err != nil {
+			return nil, err
+		}
+		value, err := cf.convert(t, baseURL)
+		if err != nil {
+			return nil, err
+		}
+		if value != nil {
+			values = append(values, value)
+		}
+	}
+	return values, nil
+}
+
+// extract returns the value of the field within item
+func (cf compiledField) extract(item *goquery.Selection, baseURL string) (any, error) {
+	ft := fieldTag{selector: cf.field.Selector, attr: cf.field.Attr, format: cf.field.Format}
+	switch cf.field.Type {
+	case FieldHTML:
+		ft.html = true
+	case FieldAttr, "", FieldText, FieldInt, FieldFloat, FieldTime:
+		if ft.attr == "" {
+			ft.attr = GetAttrName(ft.selector)
+		}
+	}
+
+	matches := findAll(item, ft.selector)
+	if !cf.field.Multiple {
+		if len(matches) == 0 {
+			return nil, nil
+		}
+		return cf.convert(selectionText(matches[0], ft), baseURL)
+	}
+
+	values := make([]any, 0, len(matches))
+	for _, match := range matches {
+		value, err := cf.convert(selectionText(match, ft), baseURL)
+		if err != nil {
+			return nil, err
+		}
+		if value != nil {
+			values = append(values, value)
+		}
+	}
+	return values, nil
+}
+
+// convert post-processes text and converts it to the field type
+// Empty text yields nil
+func (cf compiledField) convert(text, baseURL string) (any, error) {
+	for _, fn := range cf.process {
+		text = fn(text, baseURL)
+	}
+	if text == "" {
+		return nil, nil
+	}
+
+	switch cf.field.Type {
+	case FieldInt:
+		val, err := parseFloat(text)
+		if err != nil {
+			return nil, err
+		}
+		return int(val), nil
+	case FieldFloat:
+		return parseFloat(text)
+	case FieldTime:
+		parsed, err := parseTime(text, cf.field.Format)
+		if err != nil {
+			return nil, err
+		}
+		return *parsed, nil
+	default:
+		return text, nil
+	}
+}
@@ -0,0 +1,476 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testJobYAML = `
+name: products
+url: %URL%
+options:
+  max_retries: 1
+  rate_limit:
+    delay: 1ms
+pagination:
+  next_page_selector: a.next[href]
+item_selector: div.product
+fields:
+  name:
+    selector: h2
+    process: [squash, upper]
+  price:
+    selector: span.price
+    type: float
+  stock:
+    selector: span.stock
+    type: int
+    process: ["regex:(\\d+) left"]
+  link:
+    selector: a.details
+    type: attr
+    attr: href
+    process: [absolute_url]
+  added:
+    selector: time
+    type: time
+    format: "2006-01-02"
+  tags:
+    selector: li
+    multiple: true
+`
+
+func newJobServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			_, _ = w.Write([]byte(`
+				<div class="product">
+					<h2>  Red   shoe </h2><span class="price">$10.50</span><span class="stock">3 left</span>
+					<a class="details" href="/p/1">More</a><time>2024-01-02</time>
+					<ul><li>a</li><li>b</li></ul>
+				</div>
+				<a class="next" href="/page2">Next</a>`))
+		case "/page2":
+			_, _ = w.Write([]byte(`
+				<div class="product">
+					<h2>Blue shoe</h2><span class="price">20</span><span class="stock">sold out</span>
+				</div>`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+// TestRunJob verifies a YAML job is executed through pagination and fields are typed
+func TestRunJob(t *testing.T) {
+	server := newJobServer()
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "job.yaml")
+	content := strings.ReplaceAll(testJobYAML, "%URL%", server.URL+"/")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	job, err := LoadJob(path)
+	if err != nil {
+		t.Fatalf("LoadJob() error = %v", err)
+	}
+
+	records, err := RunJob(context.Background(), job)
+	if err != nil {
+		t.Fatalf("RunJob() error = %v", err)
+	}
+
+	var items []map[string]any
+	for record := range records {
+		if record.Err != nil {
+			t.Errorf("Received error from channel: %v", record.Err)
+			continue
+		}
+		items = append(items, record.Data)
+	}
+
+	if len(items) != 2 {
+		t.Fatalf("Expected 2 items, got %d", len(items))
+	}
+
+	first := items[0]
+	if first["name"] != "RED SHOE" {
+		t.Errorf("name = %v", first["name"])
+	}
+	if first["price"] != 10.5 {
+		t.Errorf("price = %v", first["price"])
+	}
+	if first["stock"] != 3 {
+		t.Errorf("stock = %v", first["stock"])
+	}
+	if first["link"] != server.URL+"/p/1" {
+		t.Errorf("link = %v", first["link"])
+	}
+	if added, ok := first["added"].(time.Time); !ok || added.Day() != 2 {
+		t.Errorf("added = %v", first["added"])
+	}
+	if tags, ok := first["tags"].([]any); !ok || len(tags) != 2 {
+		t.Errorf("tags = %v", first["tags"])
+	}
+
+	second := items[1]
+	if second["stock"] != nil || second["link"] != nil {
+		t.Errorf("Expected missing values to be nil, got stock=%v link=%v", second["stock"], second["link"])
+	}
+
+	data, err := JobRecord{Data: first}.JSON()
+	if err != nil {
+		t.Fatalf("JSON() error = %v", err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded["name"] != "RED SHOE" {
+		t.Errorf("JSON() = %s, %v", data, err)
+	}
+}
+
+// TestRunJob_TableRows verifies fields are extracted from items that cannot
+// stand alone outside their parent element, such as table rows, and links
+// resolve against <base href>
+func TestRunJob_TableRows(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<base href="/catalog/"><table>
+			<tr class="row"><td class="name"><a href="red">Red</a></td><td class="price">1.50</td></tr>
+			<tr class="row"><td class="name"><a href="blue">Blue</a></td><td class="price">2</td></tr>
+		</table>`))
+	}))
+	defer server.Close()
+
+	job, err := ParseJob([]byte(`
+url: `+server.URL+`
+options:
+  max_retries: 1
+item_selector: tr.row
+fields:
+  name:
+    selector: td.name
+  price:
+    selector: td.price
+    type: float
+  link:
+    selector: td.name a[href]
+    process: [absolute_url]
+`), "yaml")
+	if err != nil {
+		t.Fatalf("ParseJob() error = %v", err)
+	}
+
+	records, err := RunJob(context.Background(), job)
+	if err != nil {
+		t.Fatalf("RunJob() error = %v", err)
+	}
+
+	var items []map[string]any
+	for record := range records {
+		if record.Err != nil {
+			t.Fatalf("Received error from channel: %v", record.Err)
+		}
+		items = append(items, record.Data)
+	}
+
+	expected := []map[string]any{
+		{"name": "Red", "price": 1.5, "link": server.URL + "/catalog/red"},
+		{"name": "Blue", "price": 2.0, "link": server.URL + "/catalog/blue"},
+	}
+	if !reflect.DeepEqual(items, expected) {
+		t.Errorf("Records = %v, want %v", items, expected)
+	}
+}
+
+// TestRunJob_JSONAPI verifies JSON API jobs follow a cursor and read fields
+// through JSON paths
+func TestRunJob_JSONAPI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("after") {
+		case "":
+			_, _ = w.Write([]byte(`{"data": {"items": [
+				{"name": "Red", "price": {"amount": 1.5}, "tags": ["new", "sale"], "stock": true, "url": "/p/red"},
+				{"name": "Blue", "price": {"amount": 2}, "tags": [], "stock": "false", "url": "/p/blue"}
+			]}, "next": "c2"}`))
+		case "c2":
+			_, _ = w.Write([]byte(`{"data": {"items": [{"name": "Green", "price": {"amount": 3}, "stock": true, "url": "/p/green"}]}, "next": null}`))
+		default:
+			t.Errorf("Unexpected cursor %q", r.URL.Query().Get("after"))
+		}
+	}))
+	defer server.Close()
+
+	job, err := ParseJob([]byte(`
+url: `+server.URL+`/api
+options:
+  max_retries: 1
+item_selector: data.items
+pagination:
+  cursor_path: next
+  cursor_param: after
+  ordered: true
+fields:
+  name:
+    selector: name
+  price:
+    selector: price.amount
+    type: float
+  tags:
+    selector: tags
+    multiple: true
+  stock:
+    selector: stock
+  url:
+    selector: url
+    process: [absolute_url]
+`), "yaml")
+	if err != nil {
+		t.Fatalf("ParseJob() error = %v", err)
+	}
+
+	records, err := RunJob(context.Background(), job)
+	if err != nil {
+		t.Fatalf("RunJob() error = %v", err)
+	}
+
+	var items []map[string]any
+	for record := range records {
+		if record.Err != nil {
+			t.Fatalf("Received error from channel: %v", record.Err)
+		}
+		items = append(items, record.Data)
+	}
+
+	expected := []map[string]any{
+		{"name": "Red", "price": 1.5, "tags": []any{"new", "sale"}, "stock": "true", "url": server.URL + "/p/red"},
+		{"name": "Blue", "price": 2.0, "tags": []any{}, "stock": "false", "url": server.URL + "/p/blue"},
+		{"name": "Green", "price": 3.0, "tags": []any{}, "stock": "true", "url": server.URL + "/p/green"},
+	}
+	if !reflect.DeepEqual(items, expected) {
+		t.Errorf("Records = %v, want %v", items, expected)
+	}
+}
+
+// TestRunJob_RequestBody verifies jobs send their request block for every page
+// with the page number set in the body
+func TestRunJob_RequestBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var search struct {
+			Query  string `json:"query"`
+			Paging struct {
+				Page int `json:"page"`
+			} `json:"paging"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&search); err != nil || r.Method != http.MethodPost ||
+			r.Header.Get("X-Api-Key") != "secret" || r.URL.Query().Get("lang") != "en" {
+			http.Error(w, "bad search", http.StatusBadRequest)
+			return
+		}
+		_, _ = fmt.Fprintf(w, `<span class="pages">2</span><div class="item">%s %d</div>`, search.Query, search.Paging.Page)
+	}))
+	defer server.Close()
+
+	job, err := ParseJob([]byte(`
+url: `+server.URL+`/search
+options:
+  max_retries: 1
+request:
+  headers: {X-Api-Key: secret}
+  query: {lang: en}
+  json: {query: widgets, paging: {page: 1}}
+item_selector: div.item
+pagination:
+  last_page_selector: span.pages
+  page_body_field: paging.page
+  ordered: true
+fields:
+  title:
+    selector: ""
+`), "yaml")
+	if err != nil {
+		t.Fatalf("ParseJob() error = %v", err)
+	}
+
+	records, err := RunJob(context.Background(), job)
+	if err != nil {
+		t.Fatalf("RunJob() error = %v", err)
+	}
+
+	var titles []any
+	for record := range records {
+		if record.Err != nil {
+			t.Fatalf("Received error from channel: %v", record.Err)
+		}
+		titles = append(titles, record.Data["title"])
+	}
+	if expected := []any{"widgets 1", "widgets 2"}; !reflect.DeepEqual(titles, expected) {
+		t.Errorf("Titles = %v, want %v", titles, expected)
+	}
+}
+
+// TestParseJob_JSON verifies JSON job definitions and duration strings
+func TestParseJob_JSON(t *testing.T) {
+	data := `{
+		"url": "https://example.com/",
+		"item_selector": "div.item",
+		"options": {
+			"rate_limit": {"delay": "250ms"},
+			"cache_ttl": "1h",
+			"retry": {"retryable_status_codes": [500, 503], "base_backoff": "2s", "jitter": "full", "ignore_retry_after": true}
+		},
+		"pagination": {"last_page_selector": "span.pages", "next_page_url_pattern": "/p/::page::"},
+		"fields": {"title": {"selector": "h2"}}
+	}`
+
+	job, err := ParseJob([]byte(data), "json")
+	if err != nil {
+		t.Fatalf("ParseJob() error = %v", err)
+	}
+
+	opts, err := job.ScraperOptions()
+	if err != nil {
+		t.Fatalf("ScraperOptions() error = %v", err)
+	}
+	if opts.RateLimit.Delay != 250*time.Millisecond || opts.CacheTTL != time.Hour {
+		t.Errorf("Unexpected options: %+v", opts)
+	}
+	retry := opts.RetryPolicy
+	if !reflect.DeepEqual(retry.RetryableStatusCodes, []int{500, 503}) || retry.BaseBackoff != 2*time.Second ||
+		retry.Jitter != JitterFull || !retry.IgnoreRetryAfter {
+		t.Errorf("Unexpected retry policy: %+v", retry)
+	}
+	if job.PaginationConfig().NextPageURLPattern != "/p/::page::" {
+		t.Errorf("Unexpected pagination: %+v", job.PaginationConfig())
+	}
+
+	if _, err := ParseJob([]byte(`{"url": "https://example.com/", "bogus": 1}`), "json"); err == nil {
+		t.Error("Expected error for unknown key")
+	}
+}
+
+// TestJobValidate verifies validation errors point to the offending field
+func TestJobValidate(t *testing.T) {
+	data := `
+url: example.com
+request:
+  method: "GE T"
+  form: {q: widgets}
+  json: {q: widgets}
+options:
+  proxies: ["proxy.example.com:8080"]
+  proxy_rotation: shuffle
+  charset: klingon
+  retry:
+    retryable_status_codes: [42]
+    base_backoff: -1s
+    jitter: wobbly
+pagination:
+  last_page_selector: span.pages
+  max_pages: -1
+fields:
+  price:
+    selector: span.price
+    type: money
+  added:
+    type: time
+  link:
+    type: attr
+    selector: a
+  name:
+    process: [trim, "regex:("]
+`
+	_, err := ParseJob([]byte(data), "yaml")
+	if err == nil {
+		t.Fatal("Expected validation errors, got none")
+	}
+
+	expected := []string{
+		"url",
+		"item_selector",
+		"request.method",
+		"request.json",
+		"pagination.next_page_url_pattern",
+		"pagination.max_pages",
+		"options.retry.retryable_status_codes[0]",
+		"options.retry.base_backoff",
+		"options.retry.jitter",
+		"options.proxies[0]",
+		"options.proxy_rotation",
+		"options.charset",
+		"fields.price.type",
+		"fields.added.format",
+		"fields.link.attr",
+		"fields.name.process[1]",
+	}
+	assertJobFieldErrors(t, err, expected)
+}
+
+// TestJobValidate_Pagination verifies pagination modes are exclusive and
+// their dependent settings are checked
+func TestJobValidate_Pagination(t *testing.T) {
+	tests := []struct {
+		name       string
+		pagination string
+		expected   []string
+	}{
+		{"Offset", "{offset_param: offset, limit_param: limit, limit: 20}", nil},
+		{"Cursor", "{cursor_path: meta.next}", nil},
+		{"Link header", "{follow_link_header: true, max_pages: 3}", nil},
+		{
+			"Exclusive modes",
+			"{next_page_selector: a.next, offset_param: offset, follow_link_header: true}",
+			[]string{"pagination.offset_param"},
+		},
+		{"Cursor and pattern", "{next_page_url_pattern: /p/::page::, cursor_path: next}", []string{"pagination.cursor_path"}},
+		{"Limit without offset", "{limit_param: limit, limit: -1}", []string{"pagination.offset_param", "pagination.limit"}},
+		{"Cursor param without path", "{cursor_param: after}", []string{"pagination.cursor_path"}},
+		{"Page body field without body", "{page_body_field: page, last_page_selector: span.pages}", []string{"pagination.page_body_field"}},
+		{"Page body field and pattern", "{page_body_field: page, next_page_url_pattern: /p/::page::}", []string{"pagination.page_body_field"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseJob([]byte("url: https://example.com/\nitem_selector: items\npagination: "+tt.pagination+"\nfields: {name: {}}\n"), "yaml")
+			if len(tt.expected) == 0 {
+				if err != nil {
+					t.Errorf("ParseJob() error = %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("Expected validation errors, got none")
+			}
+			assertJobFieldErrors(t, err, tt.expected)
+		})
+	}
+}
+
+// assertJobFieldErrors fails unless err reports every one of fields
+func assertJobFieldErrors(t *testing.T, err error, fields []string) {
+	t.Helper()
+	for _, field := range fields {
+		found := false
+		for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+			var fieldErr *JobFieldError
+			if errors.As(e, &fieldErr) && fieldErr.Field == field {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Expected an error for %q in: %v", field, err)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package scraper
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// decodeJSON decodes body keeping numbers as json.Number so large IDs and
+// cursors survive unchanged
+func decodeJSON(body []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var v any
+	if err := decoder.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// isJSONResponse reports whether resp carries a JSON body
+// The body is only sniffed when the Content-Type is missing or text/plain
+func isJSONResponse(resp *Response) bool {
+	if resp.ContentType == "application/json" || strings.HasSuffix(resp.ContentType, "+json") {
+		return true
+	}
+	if resp.ContentType != "" && resp.ContentType != "text/plain" {
+		return false
+	}
+	body := bytes.TrimSpace(resp.Body)
+	return len(body) > 0 && (body[0] == '{' || body[0] == '[')
+}
+
+// jsonPath returns the value at path within v
+// Paths are dot separated object keys with optional [n] array indexes, e.g.
+// "data.items" or "$.links[0].href". An empty path or "$" selects v itself
+func jsonPath(v any, path string) (any, bool) {
+	path = strings.TrimPrefix(strings.TrimSpace(path), "$")
+
+	for path != "" {
+		var token string
+		switch path[0] {
+		case '.':
+			path = path[1:]
+			continue
+		case '[':
+			end := strings.IndexByte(path, ']')
+			if end < 0 {
+				return nil, false
+			}
+			index, err := strconv.Atoi(path[1:end])
+			if err != nil {
+				return nil, false
+			}
+			path = path[end+1:]
+
+			items, ok := v.([]any)
+			if !ok || index < 0 || index >= len(items) {
+				return nil, false
+			}
+			v = items[index]
+			continue
+		}
+
+		end := strings.IndexAny(path, ".[")
+		if end < 0 {
+			end = len(path)
+		}
+		token, path = path[:end], path[end:]
+
+		object, ok := v.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if v, ok = object[token]; !ok {
+			return nil, false
+		}
+	}
+
+	return v, true
+}
+
+// setJSONPath sets the value at path within the object v, creating missing
+// objects along the way. Only dot separated object keys are supported
+func setJSONPath(v any, path string, value any) error {
+	keys := strings.Split(strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(path), "$"), "."), ".")
+	for i, key := range keys {
+		object, ok := v.(map[string]any)
+		if !ok || key == "" {
+			return fmt.Errorf("invalid JSON path %q for the request body", path)
+		}
+		if i == len(keys)-1 {
+			object[key] = value
+			return nil
+		}
+		if _, ok := object[key]; !ok {
+			object[key] = map[string]any{}
+		}
+		v = object[key]
+	}
+	return nil
+}
+
+// jsonItems returns the values at path, one per element if path selects an array
+func jsonItems(v any, path string) []any {
+	value, ok := jsonPath(v, path)
+	if !ok || value == nil {
+		return nil
+	}
+	if items, ok := value.([]any); ok {
+		return items
+	}
+	return []any{value}
+}
+
+// jsonString returns a string or number value as text, empty for anything else
+func jsonString(v any) string {
+	switch value := v.(type) {
+	case string:
+		return value
+	case json.Number:
+		return value.String()
+	default:
+		return ""
+	}
+}
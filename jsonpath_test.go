@@ -0,0 +1,113 @@
+package scraper
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestJSONPath verifies keys, array indexes and missing paths
+func TestJSONPath(t *testing.T) {
+	data, err := decodeJSON([]byte(`{
+		"data": {"items": [{"id": 1}, {"id": 2}]},
+		"links": {"next": "/items?page=2"},
+		"next_cursor": 12345678901234567890
+	}`))
+	if err != nil {
+		t.Fatalf("decodeJSON() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+		found    bool
+	}{
+		{"Nested key", "links.next", "/items?page=2", true},
+		{"Root prefix", "$.links.next", "/items?page=2", true},
+		{"Array index", "data.items[1].id", "2", true},
+		{"Large number", "next_cursor", "12345678901234567890", true},
+		{"Missing key", "links.prev", "", false},
+		{"Index out of range", "data.items[2].id", "", false},
+		{"Index on object", "links[0]", "", false},
+		{"Unclosed index", "data.items[0", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, found := jsonPath(data, tt.path)
+			if found != tt.found || jsonString(value) != tt.expected {
+				t.Errorf("jsonPath(%q) = %v, %v, want %q, %v", tt.path, value, found, tt.expected, tt.found)
+			}
+		})
+	}
+
+	if items := jsonItems(data, "data.items"); len(items) != 2 {
+		t.Errorf("jsonItems() returned %d items, want 2", len(items))
+	}
+	if items := jsonItems(data, "links"); len(items) != 1 {
+		t.Errorf("jsonItems() of an object returned %d items, want 1", len(items))
+	}
+	if root, _ := jsonPath(data, "$"); root == nil {
+		t.Error("Expected $ to select the root")
+	}
+
+	encoded, _ := json.Marshal(jsonItems(data, "data.items")[0])
+	if string(encoded) != `{"id":1}` {
+		t.Errorf("Item encoded as %s", encoded)
+	}
+}
+
+// TestIsJSONResponse verifies only untyped or text/plain bodies are sniffed
+func TestIsJSONResponse(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		expected    bool
+	}{
+		{"JSON", "application/json", `{"a":1}`, true},
+		{"JSON suffix", "application/ld+json", `{"a":1}`, true},
+		{"Untyped", "", `[1, 2]`, true},
+		{"Plain text", "text/plain", ` {"a":1}`, true},
+		{"HTML starting with a bracket", "text/html", `[note] <div class="i">One</div>`, false},
+		{"XHTML", "application/xhtml+xml", `{x}`, false},
+		{"Untyped HTML", "", `<div></div>`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &Response{ContentType: tt.contentType, Body: []byte(tt.body)}
+			if got := isJSONResponse(resp); got != tt.expected {
+				t.Errorf("isJSONResponse() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestScrapePaginated_HTMLStartingWithBracket verifies HTML pages are not
+// mistaken for JSON because of their first character
+func TestScrapePaginated_HTMLStartingWithBracket(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`[note] <div class="i">One</div>`))
+	}))
+	defer server.Close()
+
+	s := New(Options{MaxRetries: 1})
+	results, err := s.ScrapePaginated(server.URL, "div.i", PaginationConfig{NextPageSelector: "a.next[href]"})
+	if err != nil {
+		t.Fatalf("ScrapePaginated() error = %v", err)
+	}
+	var items []string
+	for result := range results {
+		if result.Err != nil {
+			t.Fatalf("Received error from channel: %v", result.Err)
+		}
+		items = append(items, result.Data)
+	}
+	if len(items) != 1 || items[0] != `<div class="i">One</div>` {
+		t.Errorf("Items = %v", items)
+	}
+}
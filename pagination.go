@@ -0,0 +1,346 @@
+package scraper
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// validate checks that at most one pagination mode is configured
+// NextPageSelector, LastPageSelector and NextPageURLPattern may be combined
+// as they always could, LastPageSelector taking precedence over
+// NextPageSelector and NextPageSelector over an open-ended NextPageURLPattern
+func (c PaginationConfig) validate() error {
+	modes := 0
+	for _, set := range []bool{
+		c.NextPageSelector != "" || c.LastPageSelector != "" || c.NextPageURLPattern != "",
+		c.OffsetParam != "",
+		c.CursorPath != "",
+		c.FollowLinkHeader,
+	} {
+		if set {
+			modes++
+		}
+	}
+	if modes > 1 {
+		return errors.New("only one of NextPageSelector, NextPageURLPattern, OffsetParam, CursorPath and FollowLinkHeader may be set")
+	}
+	if c.PageBodyField != "" && (c.NextPageURLPattern != "" || c.NextPageSelector != "") {
+		return errors.New("PageBodyField cannot be combined with NextPageURLPattern or NextPageSelector")
+	}
+	if c.PageBodyField != "" && modes > 0 && c.LastPageSelector == "" {
+		return errors.New("only one of PageBodyField, OffsetParam, CursorPath and FollowLinkHeader may be set")
+	}
+
+	if c.LastPageSelector != "" && c.NextPageURLPattern == "" && c.PageBodyField == "" {
+		// NextPageURLPattern is mandatory when using LastPageSelector
+		return errors.New("NextPageURLPattern must be provided when using LastPageSelector, or PageBodyField to send the page in the request body")
+	}
+	if c.LastPageSelector == "" && c.NextPageSelector == "" && c.NextPageURLPattern != "" && !strings.Contains(c.NextPageURLPattern, "::page::") {
+		return errors.New("NextPageURLPattern must contain the ::page:: placeholder")
+	}
+	if c.MaxPages < 0 {
+		return errors.New("MaxPages must not be negative")
+	}
+	if c.OffsetParam == "" && (c.LimitParam != "" || c.Limit != 0) {
+		return errors.New("OffsetParam must be provided when using LimitParam or Limit")
+	}
+	if c.Limit < 0 {
+		return errors.New("Limit must not be negative")
+	}
+	if c.CursorParam != "" && c.CursorPath == "" {
+		return errors.New("CursorPath must be provided when using CursorParam")
+	}
+	return nil
+}
+
+// scrapePageOpenEnded paginates through NextPageURLPattern without a known last page
+// MaxParallelRequests pages are fetched ahead speculatively while pages are
+// processed in order until one has no items, is not found or repeats the
+// previous page. Pages fetched past that end are discarded
+func (s *Scraper) scrapePageOpenEnded(ctx context.Context, frontier Frontier, template *Request, selector string, config PaginationConfig, resultsChan chan<- Result) {
+	defer close(resultsChan)
+
+	workers := max(s.options.MaxParallelRequests, 1)
+	pageURL := func(page int) string {
+		if page == 1 {
+			return template.URL
+		}
+		return config.pageURL(template.URL, template.URL, page)
+	}
+	inRange := func(page int) bool {
+		return config.MaxPages <= 0 || page <= config.MaxPages
+	}
+
+	// Page URLs are deterministic, so the pages queued by a previous run of a
+	// resumed frontier can be numbered up front
+	pages := &pageNumbers{}
+	for page := 1; page <= len(frontier.Entries())+workers; page++ {
+		pages.add(page, pageURL(page))
+	}
+	for page := 2; page <= workers && inRange(page); page++ {
+		if _, err := frontier.Add(pageURL(page), 0); err != nil {
+			send(ctx, resultsChan, Result{Err: fmt.Errorf("failed to update frontier: %w", err)})
+			return
+		}
+	}
+
+	// Each page passed in order queues the page workers ahead of it, so no
+	// more than workers pages are fetched past the end
+	orderer := newPageOrderer(ctx, resultsChan, frontier, pages)
+	orderer.advance = func(page int) {
+		next := page + workers
+		if !inRange(next) {
+			return
+		}
+		pages.add(next, pageURL(next))
+		if _, err := frontier.Add(pageURL(next), 0); err != nil {
+			send(ctx, resultsChan, Result{Err: fmt.Errorf("failed to update frontier: %w", err)})
+		}
+	}
+
+	s.paginate(ctx, frontier, workers, 0, func(entry *FrontierEntry) ([]string, error) {
+		page := pages.number(entry.URL)
+		if page == 0 || !orderer.within(page) {
+			// Fetched ahead past the end
+			return nil, nil
+		}
+
+		var results []Result
+		req, err := template.forPageNumber(entry.URL, page, config)
+		var p *scrapedPage
+		if err != nil {
+			results = append(results, Result{URL: template.URL, Page: page, Err: err})
+		} else {
+			p, err = s.pushPageContents(ctx, req, page, selector, func(r Result) bool {
+				results = append(results, r)
+				return true
+			})
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		// A missing start page is an error, later ones mark the end
+		var statusErr *HTTPStatusError
+		notFound := page > 1 && errors.As(err, &statusErr) &&
+			(statusErr.StatusCode == http.StatusNotFound || statusErr.StatusCode == http.StatusGone)
+		o := &orderedPage{
+			results:   results,
+			beyondEnd: notFound || (err == nil && p.items == 0),
+			final:     err != nil,
+		}
+		if err == nil {
+			o.hash = itemsHash(results)
+		}
+		if !orderer.deliver(page, o) {
+			return nil, ctx.Err()
+		}
+		if notFound {
+			return nil, nil
+		}
+		return nil, err
+	}, resultsChan)
+
+	orderer.flushAll()
+}
+
+// bodyPageFragment marks the page number in the frontier URL of PageBodyField
+// pages, which all share the start URL
+const bodyPageFragment = "scraper-page="
+
+// pageURL returns the URL of page for page-number pagination
+// NextPageURLPattern resolves against baseURL, PageBodyField pages use startURL
+// with the page number in a fragment so the frontier tracks them separately
+func (c PaginationConfig) pageURL(baseURL, startURL string, page int) string {
+	if c.PageBodyField != "" {
+		u, _, _ := strings.Cut(startURL, "#")
+		return u + "#" + bodyPageFragment + strconv.Itoa(page)
+	}
+	return GetFullURL(baseURL, strings.ReplaceAll(c.NextPageURLPattern, "::page::", strconv.Itoa(page)))
+}
+
+// forPage returns a copy of r sent to pageURL
+func (r *Request) forPage(pageURL string) *Request {
+	req := *r
+	req.URL = pageURL
+	return &req
+}
+
+// forPageNumber returns a copy of r for page of page-number pagination, sent
+// to pageURL with the page number set in the body if PageBodyField is used
+func (r *Request) forPageNumber(pageURL string, page int, config PaginationConfig) (*Request, error) {
+	if config.PageBodyField == "" {
+		return r.forPage(pageURL), nil
+	}
+
+	req := r.forPage(r.URL)
+	var err error
+	switch contentType := parseContentType(r.Header.Get("Content-Type")); contentType {
+	case "application/x-www-form-urlencoded":
+		var form url.Values
+		if form, err = url.ParseQuery(string(r.Body)); err == nil {
+			form.Set(config.PageBodyField, strconv.Itoa(page))
+			req.Body = []byte(form.Encode())
+		}
+	case "application/json":
+		var body any
+		if body, err = decodeJSON(r.Body); err == nil {
+			if err = setJSONPath(body, config.PageBodyField, page); err == nil {
+				req.Body, err = json.Marshal(body)
+			}
+		}
+	default:
+		err = fmt.Errorf("cannot set %s in a %q request body", config.PageBodyField, contentType)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to set page %d in request body: %w", page, err)
+	}
+	return req, nil
+}
+
+// itemsHash identifies the items of a page
+func itemsHash(results []Result) string {
+	h := sha256.New()
+	for _, r := range results {
+		h.Write([]byte(r.Data))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// startURL returns the first page URL, adding the offset and limit parameters
+// in offset mode unless rawURL already has them
+func (c PaginationConfig) startURL(rawURL string) (string, error) {
+	if c.OffsetParam == "" {
+		return rawURL, nil
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL %q: %w", rawURL, err)
+	}
+	query := u.Query()
+	if !query.Has(c.OffsetParam) {
+		query.Set(c.OffsetParam, "0")
+	}
+	if c.LimitParam != "" && c.Limit > 0 && !query.Has(c.LimitParam) {
+		query.Set(c.LimitParam, strconv.Itoa(c.Limit))
+	}
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// nextPageURL returns the URL of the page following p, fetched from pageURL
+// Returns empty string once pagination is complete
+func nextPageURL(pageURL string, p *scrapedPage, config PaginationConfig) (string, error) {
+	switch {
+	case config.OffsetParam != "":
+		// Stop on the first empty page
+		if p.items == 0 {
+			return "", nil
+		}
+		step := config.Limit
+		if step == 0 {
+			step = p.items
+		}
+		return offsetURL(pageURL, config.OffsetParam, step)
+
+	case config.CursorPath != "":
+		if p.data == nil {
+			return "", fmt.Errorf("cursor path %q requires a JSON response from %s", config.CursorPath, p.resp.URL)
+		}
+		value, _ := jsonPath(p.data, config.CursorPath)
+		cursor := jsonString(value)
+		if cursor == "" {
+			return "", nil
+		}
+		if config.CursorParam != "" {
+			return setQueryParam(pageURL, config.CursorParam, cursor)
+		}
+		return GetFullURL(p.resp.URL, cursor), nil
+
+	case config.FollowLinkHeader:
+		if next := linkHeaderURL(p.resp.Header, "next"); next != "" {
+			return GetFullURL(p.resp.URL, next), nil
+		}
+		return "", nil
+
+	case config.NextPageSelector != "" && p.doc != nil:
+		// Resolved against <base href> or the final URL so redirects don't break relative links
+		return p.doc.GetURL(config.NextPageSelector), nil
+	}
+
+	return "", nil
+}
+
+// offsetURL returns rawURL with the offset parameter advanced by step
+func offsetURL(rawURL, param string, step int) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	query := u.Query()
+	offset := 0
+	if value := query.Get(param); value != "" {
+		if offset, err = strconv.Atoi(value); err != nil {
+			return "", fmt.Errorf("invalid %s value %q in %s", param, value, rawURL)
+		}
+	}
+	query.Set(param, strconv.Itoa(offset+step))
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// setQueryParam returns rawURL with the query parameter key set to value
+func setQueryParam(rawURL, key, value string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	query := u.Query()
+	query.Set(key, value)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// linkHeaderURL returns the target of the first Link header entry with relation rel
+// e.g. `<https://example.com/items?page=2>; rel="next"`
+func linkHeaderURL(header http.Header, rel string) string {
+	for _, value := range header.Values("Link") {
+		for value != "" {
+			start := strings.IndexByte(value, '<')
+			end := strings.IndexByte(value, '>')
+			if start < 0 || end < start {
+				break
+			}
+			// Targets may contain commas, so entries are split at the next '<'
+			target, params := value[start+1:end], value[end+1:]
+			value = ""
+			if next := strings.IndexByte(params, '<'); next >= 0 {
+				params, value = params[:next], params[next:]
+			}
+
+			for _, param := range strings.Split(params, ";") {
+				name, relTypes, ok := strings.Cut(param, "=")
+				if !ok || !strings.EqualFold(strings.TrimSpace(name), "rel") {
+					continue
+				}
+				// rel may list several space separated relation types
+				for _, r := range strings.Fields(strings.Trim(relTypes, "\" ,")) {
+					if strings.EqualFold(r, rel) {
+						return target
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
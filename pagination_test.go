@@ -0,0 +1,460 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// collectData drains resultsChan, failing the test on errors
+func collectData(t *testing.T, resultsChan <-chan Result) []string {
+	t.Helper()
+	var data []string
+	for result := range resultsChan {
+		if result.Err != nil {
+			t.Errorf("Received error from channel: %v", result.Err)
+			continue
+		}
+		data = append(data, result.Data)
+	}
+	return data
+}
+
+// TestScrapePaginated_Offset verifies offset/limit pagination stops at the first empty page
+func TestScrapePaginated_Offset(t *testing.T) {
+	const total = 5
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.RawQuery)
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+		var items []string
+		for i := offset; i < min(offset+limit, total); i++ {
+			items = append(items, fmt.Sprintf(`{"id":%d}`, i))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"results":[%s]}`, strings.Join(items, ","))
+	}))
+	defer server.Close()
+
+	s := New(Options{MaxRetries: 1})
+	resultsChan, err := s.ScrapePaginated(server.URL+"/items?sort=id", "results", PaginationConfig{
+		OffsetParam: "offset",
+		LimitParam:  "limit",
+		Limit:       2,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	data := collectData(t, resultsChan)
+	expected := []string{`{"id":0}`, `{"id":1}`, `{"id":2}`, `{"id":3}`, `{"id":4}`}
+	if !equalStrings(data, expected) {
+		t.Errorf("Results = %v, want %v", data, expected)
+	}
+
+	expectedRequests := []string{
+		"limit=2&offset=0&sort=id",
+		"limit=2&offset=2&sort=id",
+		"limit=2&offset=4&sort=id",
+		"limit=2&offset=6&sort=id",
+	}
+	if !equalStrings(requests, expectedRequests) {
+		t.Errorf("Requests = %v, want %v", requests, expectedRequests)
+	}
+}
+
+// TestScrapePaginated_Cursor verifies cursors sent as a parameter or used as the next URL
+func TestScrapePaginated_Cursor(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("cursor") + r.URL.Path {
+		case "/param":
+			_, _ = w.Write([]byte(`{"data":[{"n":1},{"n":2}],"meta":{"next_cursor":"abc"}}`))
+		case "abc/param":
+			_, _ = w.Write([]byte(`{"data":[{"n":3}],"meta":{"next_cursor":null}}`))
+		case "/links":
+			_, _ = w.Write([]byte(`{"data":[{"n":1}],"links":{"next":"/links/2"}}`))
+		case "/links/2":
+			_, _ = w.Write([]byte(`{"data":[{"n":2}],"links":{"next":""}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		path     string
+		config   PaginationConfig
+		expected []string
+	}{
+		{
+			"Cursor parameter",
+			"/param",
+			PaginationConfig{CursorPath: "meta.next_cursor", CursorParam: "cursor"},
+			[]string{`{"n":1}`, `{"n":2}`, `{"n":3}`},
+		},
+		{
+			"Next URL",
+			"/links",
+			PaginationConfig{CursorPath: "links.next"},
+			[]string{`{"n":1}`, `{"n":2}`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New(Options{MaxRetries: 1})
+			resultsChan, err := s.ScrapePaginated(server.URL+tt.path, "data", tt.config)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if data := collectData(t, resultsChan); !equalStrings(data, tt.expected) {
+				t.Errorf("Results = %v, want %v", data, tt.expected)
+			}
+		})
+	}
+}
+
+// TestScrapePaginated_LinkHeader verifies pagination through the Link response header
+func TestScrapePaginated_LinkHeader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page < 3 {
+			w.Header().Set("Link", fmt.Sprintf(`</items?page=1>; rel="first", </items?page=%d>; rel="next"`, page+1))
+		}
+		_, _ = fmt.Fprintf(w, `<div class="item">%d</div>`, page)
+	}))
+	defer server.Close()
+
+	s := New(Options{MaxRetries: 1})
+	resultsChan, err := s.ScrapePaginated(server.URL+"/items?page=1", "div.item", PaginationConfig{FollowLinkHeader: true})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	data := collectData(t, resultsChan)
+	expected := []string{`<div class="item">1</div>`, `<div class="item">2</div>`, `<div class="item">3</div>`}
+	if !equalStrings(data, expected) {
+		t.Errorf("Results = %v, want %v", data, expected)
+	}
+}
+
+// TestLinkHeaderURL verifies Link header parsing
+func TestLinkHeaderURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []string
+		expected string
+	}{
+		{"Single", []string{`<https://a.test/2>; rel="next"`}, "https://a.test/2"},
+		{"Several entries", []string{`<https://a.test/1>; rel="prev", <https://a.test/3>; rel="next"`}, "https://a.test/3"},
+		{"Several headers", []string{`<https://a.test/1>; rel=prev`, `<https://a.test/3>; rel=next`}, "https://a.test/3"},
+		{"Several relations", []string{`<https://a.test/3>; rel="last next"`}, "https://a.test/3"},
+		{"Comma in target", []string{`<https://a.test/?f=a,b>; title="x"; REL="Next"`}, "https://a.test/?f=a,b"},
+		{"No next", []string{`<https://a.test/1>; rel="prev"`}, ""},
+		{"Missing", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			for _, value := range tt.values {
+				header.Add("Link", value)
+			}
+			if got := linkHeaderURL(header, "next"); got != tt.expected {
+				t.Errorf("linkHeaderURL() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestPaginationConfig_Validate verifies conflicting or incomplete modes are rejected
+func TestPaginationConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  PaginationConfig
+		wantErr bool
+	}{
+		{"Single page", PaginationConfig{}, false},
+		{"Offset", PaginationConfig{OffsetParam: "offset", LimitParam: "limit", Limit: 50}, false},
+		{"Cursor", PaginationConfig{CursorPath: "next", CursorParam: "cursor"}, false},
+		{"Two modes", PaginationConfig{NextPageSelector: "a.next", FollowLinkHeader: true}, true},
+		{"Limit without offset", PaginationConfig{Limit: 10}, true},
+		{"Negative limit", PaginationConfig{OffsetParam: "offset", Limit: -1}, true},
+		{"Cursor param without path", PaginationConfig{CursorParam: "cursor"}, true},
+		{"Open-ended", PaginationConfig{NextPageURLPattern: "/page/::page::", MaxPages: 10}, false},
+		{"Pattern without placeholder", PaginationConfig{NextPageURLPattern: "/page/2"}, true},
+		{"Pattern with next selector", PaginationConfig{NextPageURLPattern: "/page/::page::", NextPageSelector: "a.next"}, false},
+		{"Last page with next selector", PaginationConfig{LastPageSelector: "span.pages", NextPageURLPattern: "/page/::page::", NextPageSelector: "a.next"}, false},
+		{"Next selector with cursor", PaginationConfig{NextPageSelector: "a.next", CursorPath: "next"}, true},
+		{"Pattern with Link header", PaginationConfig{NextPageURLPattern: "/page/::page::", FollowLinkHeader: true}, true},
+		{"Page body field with next selector", PaginationConfig{PageBodyField: "page", NextPageSelector: "a.next"}, true},
+		{"Page body field with cursor", PaginationConfig{PageBodyField: "page", CursorPath: "next"}, true},
+		{"Negative max pages", PaginationConfig{MaxPages: -1}, true},
+		{"Page body field", PaginationConfig{PageBodyField: "page", LastPageSelector: "span.pages"}, false},
+		{"Page body field with pattern", PaginationConfig{PageBodyField: "page", NextPageURLPattern: "/page/::page::"}, true},
+		{"Page body field with offset", PaginationConfig{PageBodyField: "page", OffsetParam: "offset"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestScrapePaginated_ModePrecedence verifies the original modes may still be
+// combined, LastPageSelector winning over NextPageSelector and NextPageSelector
+// over an open-ended NextPageURLPattern
+func TestScrapePaginated_ModePrecedence(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		if page == "" {
+			page = "1"
+		}
+		_, _ = fmt.Fprintf(w, `<span class="pages">3</span><div class="item">%s</div>`, page)
+		if page == "1" {
+			_, _ = w.Write([]byte(`<a class="next" href="/?page=9">Next</a>`))
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		config   PaginationConfig
+		expected []string
+	}{
+		{
+			"Last page selector",
+			PaginationConfig{LastPageSelector: "span.pages", NextPageURLPattern: "/?page=::page::", NextPageSelector: "a.next[href]", Ordered: true},
+			[]string{"1", "2", "3"},
+		},
+		{
+			"Next page selector",
+			PaginationConfig{NextPageURLPattern: "/?page=::page::", NextPageSelector: "a.next[href]"},
+			[]string{"1", "9"},
+		},
+	}
+
+	s := New(Options{MaxRetries: 1})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resultsChan, err := s.ScrapePaginated(server.URL, "div.item", tt.config)
+			if err != nil {
+				t.Fatalf("ScrapePaginated() error = %v", err)
+			}
+			var pages []string
+			for _, item := range collectData(t, resultsChan) {
+				texts, _ := GetText(item, "div.item")
+				pages = append(pages, texts...)
+			}
+			if strings.Join(pages, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Pages = %v, want %v", pages, tt.expected)
+			}
+		})
+	}
+}
+
+// TestScrapePaginated_OpenEnded verifies page numbers increment until the end is detected
+func TestScrapePaginated_OpenEnded(t *testing.T) {
+	const lastPage = 7
+
+	var mu sync.Mutex
+	maxRequested := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mode, rest, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+		page := 1
+		if rest != "" {
+			page, _ = strconv.Atoi(strings.TrimPrefix(rest, "page/"))
+		}
+		mu.Lock()
+		maxRequested[mode] = max(maxRequested[mode], page)
+		mu.Unlock()
+
+		// Earlier pages answer last to exercise reordering
+		time.Sleep(time.Duration(10-page%10) * time.Millisecond)
+
+		if page > lastPage {
+			switch mode {
+			case "empty":
+				_, _ = w.Write([]byte(`<p>No results</p>`))
+				return
+			case "missing":
+				w.WriteHeader(http.StatusNotFound)
+				return
+			case "repeat":
+				page = lastPage
+			}
+		}
+		_, _ = fmt.Fprintf(w, `<div class="item">%d</div>`, page)
+	}))
+	defer server.Close()
+
+	allPages := make([]string, lastPage)
+	for i := range allPages {
+		allPages[i] = fmt.Sprintf(`<div class="item">%d</div>`, i+1)
+	}
+
+	tests := []struct {
+		name     string
+		mode     string
+		maxPages int
+		expected []string
+	}{
+		{"Empty page", "empty", 0, allPages},
+		{"Not found", "missing", 0, allPages},
+		{"Repeated page", "repeat", 0, allPages},
+		{"Max pages", "repeat", 2, allPages[:2]},
+	}
+
+	const workers = 3
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New(Options{MaxRetries: 1, MaxParallelRequests: workers})
+			resultsChan, err := s.ScrapePaginated(server.URL+"/"+tt.mode+"/", "div.item", PaginationConfig{
+				NextPageURLPattern: "page/::page::",
+				MaxPages:           tt.maxPages,
+			})
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			data := collectData(t, resultsChan)
+			if !equalStrings(data, tt.expected) {
+				t.Errorf("Results = %v, want %v", data, tt.expected)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			// At most workers pages are fetched past the last page with items
+			limit := lastPage + workers
+			if tt.maxPages > 0 {
+				limit = tt.maxPages
+			}
+			if maxRequested[tt.mode] > limit {
+				t.Errorf("Requested page %d, expected at most %d", maxRequested[tt.mode], limit)
+			}
+			delete(maxRequested, tt.mode)
+		})
+	}
+}
+
+// TestScrapePaginated_PageBodyField verifies POST pagination with the page number in the body
+func TestScrapePaginated_PageBodyField(t *testing.T) {
+	const lastPage = 3
+
+	var mu sync.Mutex
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+
+		var page int
+		var query string
+		switch r.Header.Get("Content-Type") {
+		case "application/json":
+			var search struct {
+				Query  string `json:"query"`
+				Paging struct {
+					Page int `json:"page"`
+				} `json:"paging"`
+			}
+			_ = json.Unmarshal(body, &search)
+			page, query = search.Paging.Page, search.Query
+		default:
+			form, _ := url.ParseQuery(string(body))
+			page, _ = strconv.Atoi(form.Get("page"))
+			query = form.Get("q")
+		}
+
+		_, _ = fmt.Fprintf(w, `<span class="pages">%d</span>`, lastPage)
+		if page <= lastPage {
+			_, _ = fmt.Fprintf(w, `<div class="item">%s %d</div>`, query, page)
+		}
+	}))
+	defer server.Close()
+
+	expected := func(query string) []string {
+		var items []string
+		for page := 1; page <= lastPage; page++ {
+			items = append(items, fmt.Sprintf(`<div class="item">%s %d</div>`, query, page))
+		}
+		return items
+	}
+
+	tests := []struct {
+		name   string
+		req    *Request
+		config PaginationConfig
+		query  string
+	}{
+		{
+			"JSON open-ended",
+			&Request{URL: server.URL + "/search", JSON: map[string]any{"query": "shoes", "paging": map[string]any{"size": 10}}},
+			PaginationConfig{PageBodyField: "paging.page"},
+			"shoes",
+		},
+		{
+			"Form with last page",
+			&Request{URL: server.URL + "/search", Form: url.Values{"q": {"hats"}}},
+			PaginationConfig{PageBodyField: "page", LastPageSelector: "span.pages", Ordered: true},
+			"hats",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests = nil
+			s := New(Options{MaxRetries: 1, MaxParallelRequests: 2})
+			resultsChan, err := s.ScrapePaginatedRequest(context.Background(), tt.req, "div.item", tt.config)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			var data []string
+			for result := range resultsChan {
+				if result.Err != nil {
+					t.Errorf("Received error from channel: %v", result.Err)
+					continue
+				}
+				if result.URL != server.URL+"/search" {
+					t.Errorf("Result.URL = %q, want the start URL", result.URL)
+				}
+				data = append(data, result.Data)
+			}
+			if want := expected(tt.query); !equalStrings(data, want) {
+				t.Errorf("Results = %v, want %v", data, want)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			for _, request := range requests {
+				if request != "POST /search" {
+					t.Errorf("Unexpected request %s", request)
+				}
+			}
+		})
+	}
+
+	s := New(Options{MaxRetries: 1})
+	_, err := s.ScrapePaginatedRequest(context.Background(), &Request{URL: server.URL}, "div.item", PaginationConfig{PageBodyField: "page"})
+	if err == nil {
+		t.Error("Expected an error for PageBodyField without a request body")
+	}
+}
@@ -0,0 +1,174 @@
+package scraper
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// ProxyRotation controls how requests are spread over Options.Proxies
+type ProxyRotation int
+
+const (
+	// ProxyRoundRobin uses the proxies in turn
+	ProxyRoundRobin ProxyRotation = iota
+	// ProxyRandom picks a random proxy for every request
+	ProxyRandom
+	// ProxyStickyHost keeps using the same proxy for a host until it is quarantined
+	ProxyStickyHost
+)
+
+// ProxyQuarantine configures when a failing proxy is taken out of rotation
+type ProxyQuarantine struct {
+	// Failures is the number of consecutive 403 or 429 responses or connection
+	// errors after which a proxy is quarantined. Defaults to 3
+	Failures int
+	// Duration is how long a quarantined proxy is skipped. Defaults to 5m
+	Duration time.Duration
+}
+
+// proxyState tracks the health of a single proxy
+type proxyState struct {
+	url *url.URL
+	// failures counts consecutive failures
+	failures int
+	// quarantinedUntil is the time the proxy returns to rotation
+	quarantinedUntil time.Time
+}
+
+// proxyPool hands out proxies according to a rotation strategy and quarantines
+// the ones that keep failing
+type proxyPool struct {
+	rotation   ProxyRotation
+	quarantine ProxyQuarantine
+	// err reports an invalid proxy URL on every request
+	err error
+
+	mu      sync.Mutex
+	proxies []*proxyState
+	next    int
+	sticky  map[string]*proxyState
+}
+
+// newProxyPool returns a pool for proxies, nil if there are none
+func newProxyPool(proxies []string, rotation ProxyRotation, quarantine ProxyQuarantine) *proxyPool {
+	if len(proxies) == 0 {
+		return nil
+	}
+	if quarantine.Failures <= 0 {
+		quarantine.Failures = 3
+	}
+	if quarantine.Duration <= 0 {
+		quarantine.Duration = 5 * time.Minute
+	}
+
+	p := &proxyPool{rotation: rotation, quarantine: quarantine, sticky: make(map[string]*proxyState)}
+	for _, proxy := range proxies {
+		u, err := url.Parse(proxy)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			p.err = fmt.Errorf("invalid proxy URL %q", proxy)
+			return p
+		}
+		p.proxies = append(p.proxies, &proxyState{url: u})
+	}
+	return p
+}
+
+// pick returns the proxy to use for a request to rawURL
+// When every proxy is quarantined the one released first is used
+func (p *proxyPool) pick(rawURL string) (*proxyState, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	healthy := func(proxy *proxyState) bool {
+		return !now.Before(proxy.quarantinedUntil)
+	}
+
+	host := hostKey(rawURL)
+	if p.rotation == ProxyStickyHost {
+		if proxy, ok := p.sticky[host]; ok && healthy(proxy) {
+			return proxy, nil
+		}
+	}
+
+	var chosen *proxyState
+	switch p.rotation {
+	case ProxyRandom:
+		var candidates []*proxyState
+		for _, proxy := range p.proxies {
+			if healthy(proxy) {
+				candidates = append(candidates, proxy)
+			}
+		}
+		if len(candidates) > 0 {
+			chosen = candidates[rand.Intn(len(candidates))]
+		}
+	default:
+		for i := range p.proxies {
+			proxy := p.proxies[(p.next+i)%len(p.proxies)]
+			if healthy(proxy) {
+				chosen = proxy
+				p.next = (p.next + i + 1) % len(p.proxies)
+				break
+			}
+		}
+	}
+
+	if chosen == nil {
+		chosen = p.proxies[0]
+		for _, proxy := range p.proxies[1:] {
+			if proxy.quarantinedUntil.Before(chosen.quarantinedUntil) {
+				chosen = proxy
+			}
+		}
+	}
+
+	if p.rotation == ProxyStickyHost {
+		p.sticky[host] = chosen
+	}
+	return chosen, nil
+}
+
+// report records the outcome of a request sent through proxy
+// 403 and 429 responses and connection errors count as failures, any other
+// response resets the count
+func (p *proxyPool) report(proxy *proxyState, statusCode int, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	failed := statusCode == http.StatusForbidden || statusCode == http.StatusTooManyRequests ||
+		(statusCode == 0 && err != nil)
+	if !failed {
+		proxy.failures = 0
+		return
+	}
+
+	proxy.failures++
+	if proxy.failures >= p.quarantine.Failures {
+		proxy.failures = 0
+		proxy.quarantinedUntil = time.Now().Add(p.quarantine.Duration)
+	}
+}
+
+// ParseProxyRotation returns the ProxyRotation named round-robin, random or sticky
+// An empty name selects ProxyRoundRobin
+func ParseProxyRotation(name string) (ProxyRotation, error) {
+	switch name {
+	case "round-robin", "":
+		return ProxyRoundRobin, nil
+	case "random":
+		return ProxyRandom, nil
+	case "sticky":
+		return ProxyStickyHost, nil
+	default:
+		return ProxyRoundRobin, fmt.Errorf("unknown proxy rotation %q, expected round-robin, random or sticky", name)
+	}
+}
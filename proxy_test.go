@@ -0,0 +1,200 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testProxy is an HTTP proxy stand-in answering every request itself
+type testProxy struct {
+	*httptest.Server
+	mu     sync.Mutex
+	hosts  []string
+	status int
+}
+
+// newTestProxy starts a proxy stand-in replying with status and its own name
+func newTestProxy(t *testing.T, name string, status int) *testProxy {
+	t.Helper()
+	p := &testProxy{status: status}
+	p.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p.mu.Lock()
+		p.hosts = append(p.hosts, r.URL.Host)
+		p.mu.Unlock()
+
+		w.WriteHeader(p.status)
+		_, _ = fmt.Fprintf(w, `<div class="item">%s %s</div>`, name, r.URL.Host)
+	}))
+	t.Cleanup(p.Close)
+	return p
+}
+
+// requests returns the hosts requested through the proxy
+func (p *testProxy) requests() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.hosts...)
+}
+
+// TestScraper_ProxyRotation verifies requests are spread over the proxies
+func TestScraper_ProxyRotation(t *testing.T) {
+	tests := []struct {
+		name     string
+		rotation ProxyRotation
+		urls     []string
+		expected []string
+	}{
+		{
+			"Round robin",
+			ProxyRoundRobin,
+			[]string{"http://a.test/", "http://a.test/", "http://a.test/", "http://a.test/"},
+			[]string{"one a.test", "two a.test", "one a.test", "two a.test"},
+		},
+		{
+			"Sticky host",
+			ProxyStickyHost,
+			[]string{"http://a.test/", "http://b.test/", "http://a.test/", "http://b.test/"},
+			[]string{"one a.test", "two b.test", "one a.test", "two b.test"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			one := newTestProxy(t, "one", http.StatusOK)
+			two := newTestProxy(t, "two", http.StatusOK)
+			s := New(Options{MaxRetries: 1, Proxies: []string{one.URL, two.URL}, ProxyRotation: tt.rotation})
+			proxyURLs := map[string]string{"one": one.URL, "two": two.URL}
+
+			for i, url := range tt.urls {
+				resp, err := s.Fetch(url)
+				if err != nil {
+					t.Fatalf("Fetch(%s) error = %v", url, err)
+				}
+				if !strings.Contains(resp.HTML(), tt.expected[i]) {
+					t.Errorf("Request %d answered with %q, want %q", i, resp.HTML(), tt.expected[i])
+				}
+				if want := proxyURLs[strings.Fields(tt.expected[i])[0]]; resp.Proxy != want {
+					t.Errorf("Request %d Proxy = %q, want %q", i, resp.Proxy, want)
+				}
+			}
+		})
+	}
+}
+
+// TestScraper_ProxyRandom verifies random rotation only picks configured proxies
+func TestScraper_ProxyRandom(t *testing.T) {
+	one := newTestProxy(t, "one", http.StatusOK)
+	two := newTestProxy(t, "two", http.StatusOK)
+	s := New(Options{MaxRetries: 1, Proxies: []string{one.URL, two.URL}, ProxyRotation: ProxyRandom})
+
+	for range 20 {
+		if _, err := s.Fetch("http://a.test/"); err != nil {
+			t.Fatalf("Fetch() error = %v", err)
+		}
+	}
+	if total := len(one.requests()) + len(two.requests()); total != 20 {
+		t.Errorf("Proxies received %d requests, want 20", total)
+	}
+}
+
+// TestScraper_ProxyQuarantine verifies failing proxies leave the rotation
+func TestScraper_ProxyQuarantine(t *testing.T) {
+	blocked := newTestProxy(t, "blocked", http.StatusForbidden)
+	healthy := newTestProxy(t, "healthy", http.StatusOK)
+	s := New(Options{
+		MaxRetries:      1,
+		Proxies:         []string{blocked.URL, healthy.URL},
+		ProxyQuarantine: ProxyQuarantine{Failures: 2, Duration: time.Hour},
+	})
+
+	for range 8 {
+		_, _ = s.Fetch("http://a.test/")
+	}
+
+	if got := len(blocked.requests()); got != 2 {
+		t.Errorf("Blocked proxy received %d requests, want 2", got)
+	}
+	if got := len(healthy.requests()); got != 6 {
+		t.Errorf("Healthy proxy received %d requests, want 6", got)
+	}
+}
+
+// TestProxyPool_Quarantine verifies failure counting, resets and the fallback
+// when every proxy is quarantined
+func TestProxyPool_Quarantine(t *testing.T) {
+	pool := newProxyPool([]string{"http://one.test:8080", "http://two.test:8080"}, ProxyRoundRobin,
+		ProxyQuarantine{Failures: 2, Duration: time.Hour})
+	one, two := pool.proxies[0], pool.proxies[1]
+
+	// A success in between resets the count
+	pool.report(one, http.StatusTooManyRequests, nil)
+	pool.report(one, http.StatusOK, nil)
+	pool.report(one, 0, context.DeadlineExceeded)
+	if !one.quarantinedUntil.IsZero() {
+		t.Fatal("Expected proxy to stay in rotation after non-consecutive failures")
+	}
+
+	pool.report(one, http.StatusForbidden, nil)
+	for range 3 {
+		if proxy, _ := pool.pick("http://a.test/"); proxy != two {
+			t.Errorf("pick() = %s, want %s", proxy.url, two.url)
+		}
+	}
+
+	pool.report(two, 0, fmt.Errorf("connection refused"))
+	pool.report(two, 0, fmt.Errorf("connection refused"))
+	if proxy, _ := pool.pick("http://a.test/"); proxy != one {
+		t.Errorf("pick() with every proxy quarantined = %s, want the one released first", proxy.url)
+	}
+}
+
+// TestProxyPool_Invalid verifies invalid proxy URLs fail every request
+func TestProxyPool_Invalid(t *testing.T) {
+	s := New(Options{MaxRetries: 1, Proxies: []string{"proxy.test:8080"}})
+	if _, err := s.Fetch("http://a.test/"); err == nil || !strings.Contains(err.Error(), "invalid proxy URL") {
+		t.Errorf("Fetch() error = %v, want invalid proxy URL", err)
+	}
+}
+
+// TestScraper_ProxyMetadata verifies the proxy is recorded on results with
+// credentials redacted, for both built-in fetchers
+func TestScraper_ProxyMetadata(t *testing.T) {
+	proxy := newTestProxy(t, "one", http.StatusOK)
+	proxyURL := strings.Replace(proxy.URL, "http://", "http://user:secret@", 1)
+	redacted := strings.Replace(proxy.URL, "http://", "http://user:xxxxx@", 1)
+
+	fetchers := map[string]Fetcher{
+		"colly": NewCollyFetcher(Options{UserAgent: DefaultUserAgent}),
+		"http":  NewHTTPFetcher(nil, DefaultUserAgent),
+	}
+
+	for name, fetcher := range fetchers {
+		t.Run(name, func(t *testing.T) {
+			s := New(Options{MaxRetries: 1, Proxies: []string{proxyURL}, Fetcher: fetcher})
+			resultsChan, err := s.ScrapePaginated("http://a.test/items", "div.item", PaginationConfig{})
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			var results []Result
+			for result := range resultsChan {
+				results = append(results, result)
+			}
+			if len(results) != 1 || results[0].Err != nil {
+				t.Fatalf("Results = %+v, want one item", results)
+			}
+			if results[0].Proxy != redacted {
+				t.Errorf("Result.Proxy = %q, want %q", results[0].Proxy, redacted)
+			}
+			if !strings.Contains(results[0].Data, "a.test") {
+				t.Errorf("Result.Data = %q, expected the request to go through the proxy", results[0].Data)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package scraper
+
+import (
+	"context"
+	"math/rand"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimit configures how fast a Scraper may send requests to a single host
+// Limits are tracked per host and shared by every method and goroutine using
+// the same Scraper instance
+type RateLimit struct {
+	// RequestsPerSecond is the sustained request rate per host, 0 disables the limit
+	RequestsPerSecond float64
+	// Burst is the number of requests allowed at once before RequestsPerSecond applies
+	// Defaults to 1
+	Burst int
+	// Delay is the minimum time between the start of two requests to the same host
+	Delay time.Duration
+	// RandomDelay adds a random extra delay of up to this duration after Delay
+	RandomDelay time.Duration
+}
+
+// rateLimiter hands out per-host request slots according to a RateLimit
+type rateLimiter struct {
+	limit RateLimit
+
+	mu     sync.Mutex
+	hosts  map[string]*hostLimiter
+	delays map[string]time.Duration
+}
+
+// hostLimiter holds the token bucket and delay state of a single host
+type hostLimiter struct {
+	tokens      float64
+	lastRefill  time.Time
+	nextAllowed time.Time
+}
+
+func newRateLimiter(limit RateLimit) *rateLimiter {
+	if limit.Burst <= 0 {
+		limit.Burst = 1
+	}
+	return &rateLimiter{
+		limit:  limit,
+		hosts:  make(map[string]*hostLimiter),
+		delays: make(map[string]time.Duration),
+	}
+}
+
+// hostKey returns the host (including port) used to group requests
+func hostKey(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+	return strings.ToLower(u.Host)
+}
+
+// setHostDelay raises the minimum delay between requests to host to at least d
+func (l *rateLimiter) setHostDelay(host string, d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if d > l.delays[host] {
+		l.delays[host] = d
+	}
+}
+
+// reserve books the next request slot for host and returns how long to wait for it
+func (l *rateLimiter) reserve(host string, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	h, ok := l.hosts[host]
+	if !ok {
+		h = &hostLimiter{tokens: float64(l.limit.Burst), lastRefill: now}
+		l.hosts[host] = h
+	}
+
+	at := now
+
+	// Token bucket for the sustained rate, tokens may go negative to queue requests
+	if rps := l.limit.RequestsPerSecond; rps > 0 {
+		h.tokens += now.Sub(h.lastRefill).Seconds() * rps
+		h.tokens = min(h.tokens, float64(l.limit.Burst))
+		h.lastRefill = now
+		h.tokens--
+		if h.tokens < 0 {
+			at = now.Add(time.Duration(-h.tokens / rps * float64(time.Second)))
+		}
+	}
+
+	// Minimum spacing between consecutive requests
+	delay := max(l.limit.Delay, l.delays[host])
+	if l.limit.RandomDelay > 0 {
+		delay += time.Duration(rand.Int63n(int64(l.limit.RandomDelay)))
+	}
+	if delay > 0 {
+		if at.Before(h.nextAllowed) {
+			at = h.nextAllowed
+		}
+		h.nextAllowed = at.Add(delay)
+	}
+
+	return at.Sub(now)
+}
+
+// wait blocks until a request to rawURL is allowed or ctx is cancelled
+func (l *rateLimiter) wait(ctx context.Context, rawURL string) error {
+	if l == nil {
+		return nil
+	}
+
+	wait := l.reserve(hostKey(rawURL), time.Now())
+	if wait <= 0 {
+		return ctx.Err()
+	}
+	return sleepContext(ctx, wait)
+}
@@ -0,0 +1,109 @@
+package scraper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestRateLimiter_TokenBucket verifies burst and sustained rate per host
+func TestRateLimiter_TokenBucket(t *testing.T) {
+	l := newRateLimiter(RateLimit{RequestsPerSecond: 2, Burst: 2})
+	now := time.Now()
+
+	expected := []time.Duration{0, 0, 500 * time.Millisecond, 1 * time.Second}
+	for i, want := range expected {
+		if got := l.reserve("example.com", now); got != want {
+			t.Errorf("reserve #%d = %v, want %v", i+1, got, want)
+		}
+	}
+
+	// Other hosts have their own bucket
+	if got := l.reserve("other.com", now); got != 0 {
+		t.Errorf("reserve on other host = %v, want 0", got)
+	}
+
+	// Tokens refill over time
+	if got := l.reserve("example.com", now.Add(3*time.Second)); got != 0 {
+		t.Errorf("reserve after refill = %v, want 0", got)
+	}
+}
+
+// TestRateLimiter_Delay verifies minimum spacing and host delay overrides
+func TestRateLimiter_Delay(t *testing.T) {
+	l := newRateLimiter(RateLimit{Delay: 100 * time.Millisecond})
+	now := time.Now()
+
+	expected := []time.Duration{0, 100 * time.Millisecond, 200 * time.Millisecond}
+	for i, want := range expected {
+		if got := l.reserve("example.com", now); got != want {
+			t.Errorf("reserve #%d = %v, want %v", i+1, got, want)
+		}
+	}
+
+	l.setHostDelay("slow.com", time.Second)
+	l.reserve("slow.com", now)
+	if got := l.reserve("slow.com", now); got != time.Second {
+		t.Errorf("reserve with host delay = %v, want 1s", got)
+	}
+}
+
+// TestRateLimiter_RandomDelay verifies random delay stays within bounds
+func TestRateLimiter_RandomDelay(t *testing.T) {
+	l := newRateLimiter(RateLimit{Delay: 10 * time.Millisecond, RandomDelay: 20 * time.Millisecond})
+	now := time.Now()
+
+	prev := l.reserve("example.com", now)
+	for i := 0; i < 20; i++ {
+		got := l.reserve("example.com", now)
+		gap := got - prev
+		if gap < 10*time.Millisecond || gap >= 30*time.Millisecond {
+			t.Errorf("gap between requests = %v, want within [10ms, 30ms)", gap)
+		}
+		prev = got
+	}
+}
+
+// TestScrapePaginated_RateLimited verifies the limit is shared across parallel workers
+func TestScrapePaginated_RateLimited(t *testing.T) {
+	var mu sync.Mutex
+	var times []time.Time
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		times = append(times, time.Now())
+		mu.Unlock()
+		_, _ = w.Write([]byte(`<div class="item">Item</div><span class="total-pages">5</span>`))
+	}))
+	defer server.Close()
+
+	s := New(Options{
+		MaxRetries:          1,
+		MaxParallelRequests: 4,
+		RateLimit:           RateLimit{Delay: 50 * time.Millisecond},
+	})
+	resultsChan, err := s.ScrapePaginated(server.URL, "div.item", PaginationConfig{
+		LastPageSelector:   "span.total-pages",
+		NextPageURLPattern: "/page::page::",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	for result := range resultsChan {
+		if result.Err != nil {
+			t.Errorf("Received error from channel: %v", result.Err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(times) != 5 {
+		t.Fatalf("Expected 5 requests, got %d", len(times))
+	}
+	if total := times[len(times)-1].Sub(times[0]); total < 190*time.Millisecond {
+		t.Errorf("Expected requests to be spaced by the rate limit, took only %v", total)
+	}
+}
@@ -0,0 +1,218 @@
+package scraper
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// JitterStrategy controls how randomness is applied to retry backoff
+type JitterStrategy int
+
+const (
+	// JitterAdditive adds up to one second of random delay on top of the backoff
+	JitterAdditive JitterStrategy = iota
+	// JitterFull picks a random delay between zero and the backoff
+	JitterFull
+	// JitterEqual keeps half of the backoff and randomizes the other half
+	JitterEqual
+	// JitterNone uses the exact exponential backoff
+	JitterNone
+)
+
+// ParseJitterStrategy returns the JitterStrategy named additive, full, equal or none
+// An empty name selects JitterAdditive
+func ParseJitterStrategy(name string) (JitterStrategy, error) {
+	switch name {
+	case "additive", "":
+		return JitterAdditive, nil
+	case "full":
+		return JitterFull, nil
+	case "equal":
+		return JitterEqual, nil
+	case "none":
+		return JitterNone, nil
+	default:
+		return JitterAdditive, fmt.Errorf("unknown jitter strategy %q, expected additive, full, equal or none", name)
+	}
+}
+
+// DefaultRetryableStatusCodes are the HTTP status codes retried when
+// RetryPolicy.RetryableStatusCodes is empty
+var DefaultRetryableStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// RetryAttempt describes the outcome of a single request attempt
+type RetryAttempt struct {
+	// URL is the requested URL
+	URL string
+	// Attempt is the 1-based attempt number
+	Attempt int
+	// StatusCode is the HTTP status code, 0 if the request failed before a response
+	StatusCode int
+	// Err is the transport error of the attempt, if any
+	Err error
+	// Retrying reports whether another attempt will be made
+	Retrying bool
+	// Delay is the wait before the next attempt, 0 if not retrying
+	Delay time.Duration
+	// Proxy is the proxy the attempt was sent through, if any
+	Proxy string
+}
+
+// RetryPolicy configures which failures are retried and how long to wait between attempts
+// The number of attempts is bounded by Options.MaxRetries
+type RetryPolicy struct {
+	// RetryableStatusCodes lists HTTP status codes that trigger a retry
+	// Defaults to DefaultRetryableStatusCodes
+	RetryableStatusCodes []int
+	// RetryableError reports whether a transport error should be retried
+	// Defaults to IsTransientError
+	RetryableError func(err error) bool
+	// BaseBackoff is the delay before the first retry, doubled on each attempt
+	// Defaults to 1s
+	BaseBackoff time.Duration
+	// MaxBackoff caps the delay between attempts, including Retry-After values
+	// Defaults to 60s
+	MaxBackoff time.Duration
+	// Jitter selects how randomness is applied to the backoff
+	Jitter JitterStrategy
+	// MaxElapsedTime stops retrying once the total time spent would exceed it
+	// Zero means no limit
+	MaxElapsedTime time.Duration
+	// IgnoreRetryAfter disables honouring the Retry-After response header
+	IgnoreRetryAfter bool
+	// RetryNonIdempotent also retries POST, PATCH and other methods that may
+	// not be safe to repeat, only idempotent methods are retried by default
+	RetryNonIdempotent bool
+	// OnAttempt is called after every attempt, successful or not
+	OnAttempt func(attempt RetryAttempt)
+}
+
+// withDefaults returns a copy of the policy with zero values replaced by defaults
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if len(p.RetryableStatusCodes) == 0 {
+		p.RetryableStatusCodes = DefaultRetryableStatusCodes
+	}
+	if p.RetryableError == nil {
+		p.RetryableError = IsTransientError
+	}
+	if p.BaseBackoff <= 0 {
+		p.BaseBackoff = 1 * time.Second
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = 60 * time.Second
+	}
+	return p
+}
+
+// report passes attempt to the OnAttempt callback, if set
+func (p RetryPolicy) report(attempt RetryAttempt) {
+	if p.OnAttempt != nil {
+		p.OnAttempt(attempt)
+	}
+}
+
+// retriesMethod reports whether requests with method may be retried
+func (p RetryPolicy) retriesMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return p.RetryNonIdempotent
+}
+
+// shouldRetry reports whether an attempt with the given outcome should be retried
+func (p RetryPolicy) shouldRetry(statusCode int, err error) bool {
+	if err != nil && statusCode == 0 {
+		return p.RetryableError(err)
+	}
+	return slices.Contains(p.RetryableStatusCodes, statusCode)
+}
+
+// backoff returns the delay before the attempt following the given one
+func (p RetryPolicy) backoff(attempt int, resp *Response) time.Duration {
+	if !p.IgnoreRetryAfter && resp != nil {
+		if delay, ok := ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			return min(delay, p.MaxBackoff)
+		}
+	}
+
+	// Stop doubling once MaxBackoff is reached, shifting further overflows
+	delay := p.MaxBackoff
+	if shift := attempt - 1; shift < 63 && p.BaseBackoff <= p.MaxBackoff>>shift {
+		delay = p.BaseBackoff << shift
+	}
+
+	switch p.Jitter {
+	case JitterFull:
+		delay = time.Duration(rand.Int63n(int64(delay) + 1))
+	case JitterEqual:
+		delay = delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+	case JitterNone:
+	default:
+		delay += time.Duration(rand.Intn(1000)) * time.Millisecond
+	}
+
+	return min(delay, p.MaxBackoff)
+}
+
+// ParseRetryAfter parses a Retry-After header value given either as a number
+// of seconds or as an HTTP-date relative to now
+// Returns false if the value is empty or malformed
+func ParseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+
+	delay := date.Sub(now)
+	if delay < 0 {
+		delay = 0
+	}
+	return delay, true
+}
+
+// IsTransientError reports whether err is a transport failure worth retrying,
+// such as a timeout, a reset or refused connection, or an unexpected EOF
+func IsTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF)
+}
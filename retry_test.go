@@ -0,0 +1,261 @@
+package scraper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fastRetryPolicy keeps retry tests quick
+func fastRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		BaseBackoff: 10 * time.Millisecond,
+		MaxBackoff:  50 * time.Millisecond,
+		Jitter:      JitterNone,
+	}
+}
+
+// TestRetryPolicy_RetryableStatusCodes verifies 5xx responses are retried by default
+func TestRetryPolicy_RetryableStatusCodes(t *testing.T) {
+	for _, code := range []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout} {
+		t.Run(http.StatusText(code), func(t *testing.T) {
+			var attempts atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if attempts.Add(1) < 3 {
+					w.WriteHeader(code)
+					return
+				}
+				_, _ = w.Write([]byte("ok"))
+			}))
+			defer server.Close()
+
+			s := New(Options{MaxRetries: 5, RetryPolicy: fastRetryPolicy()})
+			html, err := s.ScrapeHTML(server.URL)
+			if err != nil {
+				t.Fatalf("Expected success after retries, got: %v", err)
+			}
+			if html != "ok" {
+				t.Errorf("Expected body 'ok', got %q", html)
+			}
+			if attempts.Load() != 3 {
+				t.Errorf("Expected 3 attempts, got %d", attempts.Load())
+			}
+		})
+	}
+}
+
+// TestRetryPolicy_NonRetryableStatus verifies statuses outside the policy fail immediately
+func TestRetryPolicy_NonRetryableStatus(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	policy := fastRetryPolicy()
+	policy.RetryableStatusCodes = []int{http.StatusTooManyRequests}
+	s := New(Options{MaxRetries: 5, RetryPolicy: policy})
+
+	if _, err := s.ScrapeHTML(server.URL); err == nil {
+		t.Fatal("Expected error for 503 status, got none")
+	}
+	if attempts.Load() != 1 {
+		t.Errorf("Expected 1 attempt, got %d", attempts.Load())
+	}
+}
+
+// TestRetryPolicy_Methods verifies only idempotent requests are retried unless
+// RetryNonIdempotent is set
+func TestRetryPolicy_Methods(t *testing.T) {
+	tests := []struct {
+		name          string
+		method        string
+		nonIdempotent bool
+		expected      int32
+	}{
+		{"GET", http.MethodGet, false, 3},
+		{"PUT", http.MethodPut, false, 3},
+		{"POST", http.MethodPost, false, 1},
+		{"PATCH", http.MethodPatch, false, 1},
+		{"POST opted in", http.MethodPost, true, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts.Add(1)
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}))
+			defer server.Close()
+
+			policy := fastRetryPolicy()
+			policy.RetryNonIdempotent = tt.nonIdempotent
+			s := New(Options{MaxRetries: 3, RetryPolicy: policy})
+
+			req := &Request{Method: tt.method, URL: server.URL, Body: []byte("q=1")}
+			if _, err := s.FetchRequest(context.Background(), req); err == nil {
+				t.Fatal("Expected error for 503 status, got none")
+			}
+			if attempts.Load() != tt.expected {
+				t.Errorf("Expected %d attempts, got %d", tt.expected, attempts.Load())
+			}
+		})
+	}
+}
+
+// TestRetryPolicy_NetworkError verifies connection failures are retried
+func TestRetryPolicy_NetworkError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close()
+
+	var attempts []RetryAttempt
+	policy := fastRetryPolicy()
+	policy.OnAttempt = func(a RetryAttempt) {
+		attempts = append(attempts, a)
+	}
+	s := New(Options{MaxRetries: 3, RetryPolicy: policy})
+
+	_, err := s.ScrapeHTML(url)
+	if err == nil {
+		t.Fatal("Expected error for closed server, got none")
+	}
+	if !strings.Contains(err.Error(), "after 3 attempts") {
+		t.Errorf("Expected error message to mention attempts, got: %v", err)
+	}
+	if len(attempts) != 3 {
+		t.Fatalf("Expected 3 reported attempts, got %d", len(attempts))
+	}
+	for i, a := range attempts {
+		if a.Attempt != i+1 || a.Err == nil {
+			t.Errorf("Unexpected attempt report %+v", a)
+		}
+		if wantRetry := i < 2; a.Retrying != wantRetry {
+			t.Errorf("Attempt %d: Retrying = %v, want %v", a.Attempt, a.Retrying, wantRetry)
+		}
+	}
+}
+
+// TestRetryPolicy_RetryAfter verifies the Retry-After header overrides the computed backoff
+func TestRetryPolicy_RetryAfter(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	var delays []time.Duration
+	policy := fastRetryPolicy()
+	policy.MaxBackoff = 5 * time.Second
+	policy.OnAttempt = func(a RetryAttempt) {
+		if a.Retrying {
+			delays = append(delays, a.Delay)
+		}
+	}
+	s := New(Options{MaxRetries: 3, RetryPolicy: policy})
+
+	if _, err := s.ScrapeHTML(server.URL); err != nil {
+		t.Fatalf("Expected success after retry, got: %v", err)
+	}
+	if len(delays) != 1 || delays[0] != time.Second {
+		t.Errorf("Expected a single 1s delay from Retry-After, got %v", delays)
+	}
+}
+
+// TestRetryPolicy_MaxElapsedTime verifies retries stop once the time budget is spent
+func TestRetryPolicy_MaxElapsedTime(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	policy := RetryPolicy{
+		BaseBackoff:    100 * time.Millisecond,
+		Jitter:         JitterNone,
+		MaxElapsedTime: 250 * time.Millisecond,
+	}
+	s := New(Options{MaxRetries: 10, RetryPolicy: policy})
+
+	start := time.Now()
+	_, err := s.ScrapeHTML(server.URL)
+	if err == nil {
+		t.Fatal("Expected error, got none")
+	}
+	if !strings.Contains(err.Error(), "retry time limit exceeded") {
+		t.Errorf("Expected time limit error, got: %v", err)
+	}
+	if time.Since(start) > 1*time.Second {
+		t.Errorf("Expected retries to stop early, took %v", time.Since(start))
+	}
+}
+
+// TestParseRetryAfter verifies both Retry-After forms are parsed
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{"Seconds", "120", 2 * time.Minute, true},
+		{"Zero seconds", "0", 0, true},
+		{"HTTP date", "Mon, 01 Jan 2024 12:00:30 GMT", 30 * time.Second, true},
+		{"Past HTTP date", "Mon, 01 Jan 2024 11:00:00 GMT", 0, true},
+		{"Empty", "", 0, false},
+		{"Negative", "-5", 0, false},
+		{"Garbage", "soon", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delay, ok := ParseRetryAfter(tt.value, now)
+			if ok != tt.ok || delay != tt.expected {
+				t.Errorf("ParseRetryAfter(%q) = %v, %v, want %v, %v", tt.value, delay, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
+
+// TestRetryPolicy_Backoff verifies exponential growth, capping and jitter bounds
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{BaseBackoff: time.Second, MaxBackoff: 5 * time.Second, Jitter: JitterNone}.withDefaults()
+
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, want := range expected {
+		if got := policy.backoff(i+1, nil); got != want {
+			t.Errorf("backoff(%d) = %v, want %v", i+1, got, want)
+		}
+	}
+
+	// Late attempts stay at MaxBackoff instead of overflowing the shift, this
+	// base wraps to a positive 17s at attempt 35 when shifted blindly
+	long := RetryPolicy{BaseBackoff: 1<<30 + 1, Jitter: JitterNone}.withDefaults()
+	for _, attempt := range []int{30, 34, 35, 40, 63, 64, 100} {
+		if got := long.backoff(attempt, nil); got != long.MaxBackoff {
+			t.Errorf("backoff(%d) = %v, want %v", attempt, got, long.MaxBackoff)
+		}
+	}
+
+	for _, jitter := range []JitterStrategy{JitterAdditive, JitterFull, JitterEqual} {
+		policy.Jitter = jitter
+		for i := 0; i < 50; i++ {
+			got := policy.backoff(2, nil)
+			if got < 0 || got > 3*time.Second {
+				t.Errorf("Jitter %d: backoff(2) = %v out of range", jitter, got)
+			}
+		}
+	}
+}
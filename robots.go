@@ -0,0 +1,296 @@
+package scraper
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrDisallowedByRobots is matched by errors.Is for URLs disallowed by robots.txt
+var ErrDisallowedByRobots = errors.New("disallowed by robots.txt")
+
+// RobotsDisallowedError is returned instead of fetching a URL that robots.txt
+// disallows for the configured user agent
+type RobotsDisallowedError struct {
+	URL       string
+	UserAgent string
+}
+
+// Error implements the error interface
+func (e *RobotsDisallowedError) Error() string {
+	return fmt.Sprintf("%s is disallowed by robots.txt for user agent %q", e.URL, e.UserAgent)
+}
+
+// Is reports whether target is ErrDisallowedByRobots
+func (e *RobotsDisallowedError) Is(target error) bool {
+	return target == ErrDisallowedByRobots
+}
+
+// robotsRule is a single Allow or Disallow line
+type robotsRule struct {
+	allow   bool
+	pattern string
+	re      *regexp.Regexp
+}
+
+// robotsGroup holds the rules applying to a set of user agents
+type robotsGroup struct {
+	agents     []string
+	rules      []robotsRule
+	crawlDelay time.Duration
+}
+
+// robotsRules is the group of a robots.txt that applies to our user agent
+// A nil *robotsRules allows everything
+type robotsRules struct {
+	rules      []robotsRule
+	crawlDelay time.Duration
+}
+
+// robotsProductToken returns the product token robots.txt groups are matched
+// against, e.g. "MyBot" for "MyBot/1.2 (+https://example.com/bot)"
+func robotsProductToken(userAgent string) string {
+	token := strings.TrimSpace(userAgent)
+	if i := strings.IndexAny(token, "/ "); i >= 0 {
+		token = token[:i]
+	}
+	return strings.ToLower(token)
+}
+
+// parseRobots parses a robots.txt body and selects the group for userAgent
+// following RFC 9309: a group naming the product token wins over "*"
+func parseRobots(body, userAgent string) *robotsRules {
+	var groups []*robotsGroup
+	var current *robotsGroup
+	inAgents := false
+
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		value = strings.TrimSpace(value)
+
+		switch name {
+		case "user-agent":
+			// Consecutive user-agent lines share one group
+			if !inAgents {
+				current = &robotsGroup{}
+				groups = append(groups, current)
+				inAgents = true
+			}
+			current.agents = append(current.agents, strings.ToLower(value))
+		case "allow", "disallow":
+			inAgents = false
+			if current == nil {
+				continue
+			}
+			// An empty Disallow allows everything and adds no rule
+			if value != "" {
+				current.rules = append(current.rules, robotsRule{
+					allow:   name == "allow",
+					pattern: value,
+					re:      compileRobotsPattern(value),
+				})
+			}
+		case "crawl-delay":
+			inAgents = false
+			if current == nil {
+				continue
+			}
+			if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+				current.crawlDelay = time.Duration(seconds * float64(time.Second))
+			}
+		default:
+			inAgents = false
+		}
+	}
+
+	token := robotsProductToken(userAgent)
+	var matched, wildcard []*robotsGroup
+	for _, group := range groups {
+		for _, agent := range group.agents {
+			if agent == token {
+				matched = append(matched, group)
+			} else if agent == "*" {
+				wildcard = append(wildcard, group)
+			}
+		}
+	}
+	if len(matched) == 0 {
+		matched = wildcard
+	}
+	if len(matched) == 0 {
+		return nil
+	}
+
+	// Groups naming the same agent are merged
+	rules := &robotsRules{}
+	for _, group := range matched {
+		rules.rules = append(rules.rules, group.rules...)
+		rules.crawlDelay = max(rules.crawlDelay, group.crawlDelay)
+	}
+	return rules
+}
+
+// allowed reports whether path (including the query) may be fetched
+// The longest matching rule wins, Allow wins ties
+func (r *robotsRules) allowed(path string) bool {
+	if r == nil {
+		return true
+	}
+
+	allow, longest := true, -1
+	for _, rule := range r.rules {
+		if !rule.re.MatchString(path) {
+			continue
+		}
+		if len(rule.pattern) > longest || (len(rule.pattern) == longest && rule.allow) {
+			allow, longest = rule.allow, len(rule.pattern)
+		}
+	}
+	return allow
+}
+
+// compileRobotsPattern converts a robots.txt path pattern into a regexp
+// supporting the "*" wildcard and the "$" end anchor
+func compileRobotsPattern(pattern string) *regexp.Regexp {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+
+	parts := strings.Split(pattern, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	expr := "^" + strings.Join(parts, ".*")
+	if anchored {
+		expr += "$"
+	}
+	return regexp.MustCompile(expr)
+}
+
+// robotsEntry is the cached robots.txt of one origin
+type robotsEntry struct {
+	ready chan struct{}
+	rules *robotsRules
+	err   error
+}
+
+// robotsCache fetches and caches robots.txt per origin
+type robotsCache struct {
+	mu      sync.Mutex
+	entries map[string]*robotsEntry
+}
+
+func newRobotsCache() *robotsCache {
+	return &robotsCache{entries: make(map[string]*robotsEntry)}
+}
+
+// robotsCheck returns a *RobotsDisallowedError if robots.txt disallows rawURL
+// Crawl-delay of the matching group is applied to the host rate limit
+func (s *Scraper) robotsCheck(ctx context.Context, rawURL string) error {
+	if s.robots == nil {
+		return nil
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil
+	}
+	// robots.txt itself is always allowed
+	if u.Path == "/robots.txt" {
+		return nil
+	}
+
+	rules, err := s.robotsRules(ctx, u)
+	if err != nil {
+		return err
+	}
+
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	if !rules.allowed(path) {
+		return &RobotsDisallowedError{URL: rawURL, UserAgent: s.options.UserAgent}
+	}
+	return nil
+}
+
+// robotsRules returns the rules of the origin of u, fetching robots.txt once
+// Concurrent callers wait for the same fetch, failed fetches are retried on the next call
+func (s *Scraper) robotsRules(ctx context.Context, u *url.URL) (*robotsRules, error) {
+	origin := strings.ToLower(u.Scheme + "://" + u.Host)
+
+	s.robots.mu.Lock()
+	entry, ok := s.robots.entries[origin]
+	if !ok {
+		entry = &robotsEntry{ready: make(chan struct{})}
+		s.robots.entries[origin] = entry
+	}
+	s.robots.mu.Unlock()
+
+	if ok {
+		select {
+		case <-entry.ready:
+			return entry.rules, entry.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	entry.rules, entry.err = s.fetchRobots(ctx, origin)
+	if entry.err != nil {
+		s.robots.mu.Lock()
+		delete(s.robots.entries, origin)
+		s.robots.mu.Unlock()
+	} else if entry.rules != nil && entry.rules.crawlDelay > 0 {
+		s.limiter.setHostDelay(hostKey(origin), entry.rules.crawlDelay)
+	}
+	close(entry.ready)
+
+	return entry.rules, entry.err
+}
+
+// fetchRobots downloads and parses the robots.txt of origin
+// A missing robots.txt (4xx) allows everything, server errors fail the check
+func (s *Scraper) fetchRobots(ctx context.Context, origin string) (*robotsRules, error) {
+	robotsURL := origin + "/robots.txt"
+	if err := s.limiter.wait(ctx, robotsURL); err != nil {
+		return nil, err
+	}
+
+	resp, err := s.fetch(ctx, &Request{Method: http.MethodGet, URL: robotsURL})
+	if err != nil && resp == nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", robotsURL, err)
+	}
+	if resp.URL == "" {
+		resp.URL = robotsURL
+	}
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return parseRobots(string(resp.Body), s.options.UserAgent), nil
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("failed to fetch %s: %w", robotsURL, statusError(resp))
+	}
+}
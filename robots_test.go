@@ -0,0 +1,158 @@
+package scraper
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testRobotsTxt = `
+# Comments are ignored
+User-agent: *
+Disallow: /private
+Allow: /private/open
+Disallow: /*.pdf$
+Disallow: /search?q=
+
+User-agent: MyBot
+User-agent: OtherBot
+Disallow: /bots-only
+Crawl-delay: 0.5
+
+User-agent: MyBot
+Disallow: /more
+`
+
+// TestParseRobots verifies group selection and allow/disallow precedence
+func TestParseRobots(t *testing.T) {
+	tests := []struct {
+		name      string
+		userAgent string
+		path      string
+		expected  bool
+	}{
+		{"Wildcard group allows", "Mozilla/5.0 (X11)", "/public", true},
+		{"Wildcard group disallows", "Mozilla/5.0 (X11)", "/private/page", false},
+		{"Longer allow wins", "Mozilla/5.0 (X11)", "/private/open/page", true},
+		{"End anchor", "Mozilla/5.0 (X11)", "/files/report.pdf", false},
+		{"End anchor mismatch", "Mozilla/5.0 (X11)", "/files/report.pdf.html", true},
+		{"Query", "Mozilla/5.0 (X11)", "/search?q=shoes", false},
+		{"Named group replaces wildcard", "MyBot/1.0", "/private/page", true},
+		{"Named group rules", "MyBot/1.0", "/bots-only", false},
+		{"Named groups merged", "mybot", "/more/page", false},
+		{"Shared group", "OtherBot/2.0 (+https://example.com)", "/bots-only", false},
+		{"Shared group only", "OtherBot/2.0", "/more", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := parseRobots(testRobotsTxt, tt.userAgent)
+			if got := rules.allowed(tt.path); got != tt.expected {
+				t.Errorf("allowed(%q) for %q = %v, want %v", tt.path, tt.userAgent, got, tt.expected)
+			}
+		})
+	}
+
+	if rules := parseRobots(testRobotsTxt, "MyBot/1.0"); rules.crawlDelay != 500*time.Millisecond {
+		t.Errorf("Expected crawl delay of 500ms, got %v", rules.crawlDelay)
+	}
+	if rules := parseRobots("User-agent: Other\nDisallow: /", "MyBot"); !rules.allowed("/anything") {
+		t.Error("Expected no matching group to allow everything")
+	}
+	if rules := parseRobots("User-agent: *\nDisallow:", "MyBot"); !rules.allowed("/anything") {
+		t.Error("Expected an empty Disallow to allow everything")
+	}
+}
+
+// TestScraper_RespectRobots verifies disallowed URLs are not fetched and Crawl-delay is applied
+func TestScraper_RespectRobots(t *testing.T) {
+	var mu sync.Mutex
+	hits := make(map[string]int)
+	var pageTimes []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		hits[r.URL.Path]++
+		if r.URL.Path == "/robots.txt" {
+			_, _ = w.Write([]byte("User-agent: TestBot\nDisallow: /private\nCrawl-delay: 0.2\n"))
+			return
+		}
+		pageTimes = append(pageTimes, time.Now())
+		_, _ = w.Write([]byte(`<div class="item">ok</div>`))
+	}))
+	defer server.Close()
+
+	s := New(Options{MaxRetries: 1, UserAgent: "TestBot/1.0", RespectRobots: true})
+
+	_, err := s.Fetch(server.URL + "/private/page")
+	var disallowed *RobotsDisallowedError
+	if !errors.As(err, &disallowed) || !errors.Is(err, ErrDisallowedByRobots) {
+		t.Fatalf("Expected RobotsDisallowedError, got: %v", err)
+	}
+	if disallowed.URL != server.URL+"/private/page" {
+		t.Errorf("Unexpected URL in error: %q", disallowed.URL)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := s.Fetch(server.URL + "/public"); err != nil {
+			t.Fatalf("Fetch() error = %v", err)
+		}
+	}
+
+	// Disallowed URLs surface as typed errors on the results channel
+	resultsChan, err := s.ScrapePaginated(server.URL+"/private/list", "div.item", PaginationConfig{})
+	if err != nil {
+		t.Fatalf("ScrapePaginated() error = %v", err)
+	}
+	for result := range resultsChan {
+		if !errors.Is(result.Err, ErrDisallowedByRobots) {
+			t.Errorf("Expected ErrDisallowedByRobots on channel, got %v", result.Err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if hits["/robots.txt"] != 1 {
+		t.Errorf("Expected robots.txt to be fetched once, got %d", hits["/robots.txt"])
+	}
+	if hits["/private/page"] != 0 || hits["/private/list"] != 0 {
+		t.Errorf("Disallowed pages were fetched: %v", hits)
+	}
+	if len(pageTimes) == 2 && pageTimes[1].Sub(pageTimes[0]) < 150*time.Millisecond {
+		t.Errorf("Expected Crawl-delay between requests, got %v", pageTimes[1].Sub(pageTimes[0]))
+	}
+}
+
+// TestScraper_RobotsUnavailable verifies missing robots.txt allows and server errors fail
+func TestScraper_RobotsUnavailable(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{"Not found", http.StatusNotFound, false},
+		{"Server error", http.StatusInternalServerError, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/robots.txt" {
+					w.WriteHeader(tt.status)
+					return
+				}
+				_, _ = w.Write([]byte("ok"))
+			}))
+			defer server.Close()
+
+			s := New(Options{MaxRetries: 1, RespectRobots: true})
+			_, err := s.Fetch(server.URL + "/page")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Fetch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
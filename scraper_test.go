@@ -1,10 +1,17 @@
 package scraper
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -154,6 +161,154 @@ func TestScrapeHTML_MaxRetriesExceeded(t *testing.T) {
 	}
 }
 
+// TestFetch verifies the rich response metadata
+func TestFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/new", http.StatusMovedPermanently)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("ETag", `"abc"`)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("<html><body><h1>New</h1></body></html>"))
+	}))
+	defer server.Close()
+
+	s := New(Options{MaxRetries: 1})
+	resp, err := s.Fetch(server.URL + "/old")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", resp.StatusCode)
+	}
+	if resp.URL != server.URL+"/new" {
+		t.Errorf("Expected final URL %q, got %q", server.URL+"/new", resp.URL)
+	}
+	if resp.RequestURL != server.URL+"/old" {
+		t.Errorf("Expected request URL %q, got %q", server.URL+"/old", resp.RequestURL)
+	}
+	if resp.ContentType != "text/html" {
+		t.Errorf("Expected content type 'text/html', got %q", resp.ContentType)
+	}
+	if resp.Header.Get("ETag") != `"abc"` {
+		t.Errorf("Expected ETag header, got %q", resp.Header.Get("ETag"))
+	}
+	if resp.FetchedAt.IsZero() || resp.Duration <= 0 {
+		t.Errorf("Expected timing to be recorded, got %v / %v", resp.FetchedAt, resp.Duration)
+	}
+	if resp.Attempts != 1 {
+		t.Errorf("Expected 1 attempt, got %d", resp.Attempts)
+	}
+	if !strings.Contains(resp.HTML(), "<h1>New</h1>") {
+		t.Errorf("Expected body to contain '<h1>New</h1>', got: %s", resp.HTML())
+	}
+}
+
+// TestFetchRequest verifies methods, headers, query parameters, bodies and
+// timeouts reach the server
+func TestFetchRequest(t *testing.T) {
+	var slowRequests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			slowRequests.Add(1)
+			time.Sleep(200 * time.Millisecond)
+		}
+		body, _ := io.ReadAll(r.Body)
+		_, _ = fmt.Fprintf(w, `<p class="echo">%s|%s|%s|%s|%s</p>`,
+			r.Method, r.Header.Get("Accept-Language"), r.URL.RawQuery, r.Header.Get("Content-Type"), body)
+	}))
+	defer server.Close()
+
+	s := New(Options{MaxRetries: 2, RetryPolicy: RetryPolicy{BaseBackoff: time.Millisecond}})
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		req      *Request
+		expected string
+	}{
+		{
+			"Headers and query",
+			&Request{URL: server.URL, Header: http.Header{"Accept-Language": {"de"}}, Query: url.Values{"q": {"shoes"}}},
+			"GET|de|q=shoes||",
+		},
+		{
+			"Form",
+			&Request{URL: server.URL, Form: url.Values{"q": {"shoes"}, "page": {"2"}}},
+			"POST|||application/x-www-form-urlencoded|page=2&amp;q=shoes",
+		},
+		{
+			"JSON",
+			&Request{URL: server.URL, JSON: map[string]string{"q": "shoes"}},
+			`POST|||application/json|{&#34;q&#34;:&#34;shoes&#34;}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, err := s.ScrapeOuterHTMLRequest(ctx, tt.req, "p.echo")
+			if err != nil {
+				t.Fatalf("ScrapeOuterHTMLRequest() error = %v", err)
+			}
+			expected := `<p class="echo">` + tt.expected + `</p>`
+			if len(items) != 1 || items[0] != expected {
+				t.Errorf("ScrapeOuterHTMLRequest() = %v, want %s", items, expected)
+			}
+		})
+	}
+
+	t.Run("Timeout", func(t *testing.T) {
+		_, err := s.FetchRequest(ctx, &Request{URL: server.URL + "/slow", Timeout: 50 * time.Millisecond})
+		if !errors.Is(err, ErrMaxRetriesExceeded) {
+			t.Errorf("FetchRequest() error = %v, want ErrMaxRetriesExceeded", err)
+		}
+		if got := slowRequests.Load(); got != 2 {
+			t.Errorf("Expected every attempt to time out and be retried, got %d requests", got)
+		}
+	})
+}
+
+// TestScrapePaginated_RedirectedNextPage verifies next links resolve against the final URL
+func TestScrapePaginated_RedirectedNextPage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			http.Redirect(w, r, "/catalog/", http.StatusFound)
+		case "/catalog/":
+			_, _ = w.Write([]byte(`<div class="item">Page 1</div><a class="next" href="/catalog/2">Next</a>`))
+		case "/catalog/2":
+			_, _ = w.Write([]byte(`<div class="item">Page 2</div>`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	s := New(Options{MaxRetries: 1})
+	resultsChan, err := s.ScrapePaginated(server.URL+"/", "div.item", PaginationConfig{
+		NextPageSelector: "a.next[href]",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	count := 0
+	for result := range resultsChan {
+		if result.Err != nil {
+			t.Errorf("Received error from channel: %v", result.Err)
+			continue
+		}
+		count++
+	}
+
+	if count != 2 {
+		t.Errorf("Expected 2 results, got %d", count)
+	}
+}
+
 // TestScrapeOuterHTML verifies element extraction
 func TestScrapeOuterHTML(t *testing.T) {
 	htmlContent := `
@@ -339,6 +494,99 @@ func TestScrapePaginated_Parallel(t *testing.T) {
 	}
 }
 
+// TestScrapePaginated_Metadata verifies results carry their page, position and fetch details
+func TestScrapePaginated_Metadata(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			_, _ = w.Write([]byte(`<div class="item">A</div><div class="item">B</div><a class="next" href="/page2">Next</a>`))
+		case "/page2":
+			_, _ = w.Write([]byte(`<div class="item">C</div>`))
+		}
+	}))
+	defer server.Close()
+
+	s := New(Options{MaxRetries: 1})
+	start := time.Now()
+	resultsChan, err := s.ScrapePaginated(server.URL+"/", "div.item", PaginationConfig{NextPageSelector: "a.next[href]"})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	expected := []struct {
+		path  string
+		page  int
+		index int
+	}{
+		{"/", 1, 0},
+		{"/", 1, 1},
+		{"/page2", 2, 0},
+	}
+
+	var results []Result
+	for result := range resultsChan {
+		results = append(results, result)
+	}
+	if len(results) != len(expected) {
+		t.Fatalf("Expected %d results, got %d", len(expected), len(results))
+	}
+
+	for i, want := range expected {
+		got := results[i]
+		if got.URL != server.URL+want.path || got.Page != want.page || got.Index != want.index {
+			t.Errorf("Result %d = %s page %d index %d, want %s page %d index %d",
+				i, got.URL, got.Page, got.Index, want.path, want.page, want.index)
+		}
+		if got.StatusCode != http.StatusOK || got.Attempts != 1 || got.FetchedAt.Before(start) {
+			t.Errorf("Result %d has unexpected fetch details: %+v", i, got)
+		}
+	}
+}
+
+// TestScrapePaginated_Ordered verifies ordered parallel pagination delivers pages in order
+func TestScrapePaginated_Ordered(t *testing.T) {
+	const lastPage = 5
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := 1
+		if r.URL.Path != "/" {
+			page, _ = strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/page"))
+		}
+		// Earlier pages answer last
+		time.Sleep(time.Duration(lastPage-page) * 20 * time.Millisecond)
+		_, _ = fmt.Fprintf(w, `<div class="item">%d-a</div><div class="item">%d-b</div><span class="pages">%d</span>`, page, page, lastPage)
+	}))
+	defer server.Close()
+
+	s := New(Options{MaxRetries: 1, MaxParallelRequests: lastPage})
+	resultsChan, err := s.ScrapePaginated(server.URL+"/", "div.item", PaginationConfig{
+		LastPageSelector:   "span.pages",
+		NextPageURLPattern: "/page::page::",
+		Ordered:            true,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	var got []string
+	for result := range resultsChan {
+		if result.Err != nil {
+			t.Errorf("Received error from channel: %v", result.Err)
+			continue
+		}
+		doc, _ := NewDocument(result.Data)
+		got = append(got, fmt.Sprintf("%d:%s", result.Page, doc.Text()))
+	}
+
+	var expected []string
+	for page := 1; page <= lastPage; page++ {
+		expected = append(expected, fmt.Sprintf("%d:%d-a", page, page), fmt.Sprintf("%d:%d-b", page, page))
+	}
+	if !equalStrings(got, expected) {
+		t.Errorf("Results = %v, want %v", got, expected)
+	}
+}
+
 // TestScrapePaginated_MissingNextPageURLPattern verifies error when required config is missing
 func TestScrapePaginated_MissingNextPageURLPattern(t *testing.T) {
 	opts := Options{MaxRetries: 1}
@@ -396,6 +644,100 @@ func TestScrapePaginated_NoNextPage(t *testing.T) {
 	}
 }
 
+// TestScrapeHTMLContext_CancelDuringBackoff verifies cancellation interrupts the retry backoff
+func TestScrapeHTMLContext_CancelDuringBackoff(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	s := New(Options{MaxRetries: 5})
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := s.ScrapeHTMLContext(ctx, server.URL)
+	duration := time.Since(start)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected context.DeadlineExceeded, got: %v", err)
+	}
+
+	if duration > 1*time.Second {
+		t.Errorf("Expected backoff to be interrupted, took %v", duration)
+	}
+}
+
+// TestScrapeHTMLContext_CancelInFlight verifies cancellation aborts a slow request
+func TestScrapeHTMLContext_CancelInFlight(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	s := New(Options{MaxRetries: 1})
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	_, err := s.ScrapeHTMLContext(ctx, server.URL)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected context.DeadlineExceeded, got: %v", err)
+	}
+}
+
+// TestScrapePaginatedContext_Cancel verifies the results channel is closed after cancellation
+func TestScrapePaginatedContext_Cancel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`<html><body>
+			<div class="item">Item</div>
+			<div class="item">Item</div>
+			<a class="next" href="/next">Next</a>
+			<span class="total-pages">1000</span>
+		</body></html>`))
+	}))
+	defer server.Close()
+
+	configs := map[string]PaginationConfig{
+		"Sequential": {NextPageSelector: "a.next[href]"},
+		"Parallel":   {LastPageSelector: "span.total-pages", NextPageURLPattern: "/page::page::"},
+	}
+
+	for name, config := range configs {
+		t.Run(name, func(t *testing.T) {
+			s := New(Options{MaxRetries: 1})
+			ctx, cancel := context.WithCancel(context.Background())
+
+			resultsChan, err := s.ScrapePaginatedContext(ctx, server.URL, "div.item", config)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			// Read a single result, then cancel without draining
+			<-resultsChan
+			cancel()
+
+			done := make(chan struct{})
+			go func() {
+				for range resultsChan {
+				}
+				close(done)
+			}()
+
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatal("Expected results channel to be closed after cancellation")
+			}
+		})
+	}
+}
+
 // TestOptions_DefaultUserAgent verifies default user agent is set
 func TestOptions_DefaultUserAgent(t *testing.T) {
 	opts := Options{}
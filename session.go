@@ -0,0 +1,318 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Session is a cookie jar shared by every request of a Scraper
+// It implements http.CookieJar and can be saved to and loaded from disk
+type Session struct {
+	jar *cookiejar.Jar
+
+	mu sync.Mutex
+	// cookies keeps every stored cookie for Save, keyed by name, domain and path
+	cookies map[string]savedCookie
+}
+
+// savedCookie is a cookie as written by Session.Save
+type savedCookie struct {
+	// URL is the URL the cookie was set by
+	URL      string    `json:"url"`
+	Name     string    `json:"name"`
+	Value    string    `json:"value"`
+	Domain   string    `json:"domain,omitempty"`
+	Path     string    `json:"path,omitempty"`
+	Expires  time.Time `json:"expires,omitzero"`
+	Secure   bool      `json:"secure,omitempty"`
+	HTTPOnly bool      `json:"http_only,omitempty"`
+}
+
+// NewSession returns an empty Session
+func NewSession() *Session {
+	jar, _ := cookiejar.New(nil)
+	return &Session{jar: jar, cookies: make(map[string]savedCookie)}
+}
+
+// LoadSession returns the Session saved at path
+// A missing file yields an empty Session so the first run can create it
+func LoadSession(path string) (*Session, error) {
+	s := NewSession()
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session %s: %w", path, err)
+	}
+
+	var cookies []savedCookie
+	if err := json.Unmarshal(data, &cookies); err != nil {
+		return nil, fmt.Errorf("failed to parse session %s: %w", path, err)
+	}
+	for _, c := range cookies {
+		u, err := url.Parse(c.URL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse session %s: invalid cookie URL %q", path, c.URL)
+		}
+		s.SetCookies(u, []*http.Cookie{{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   c.Domain,
+			Path:     c.Path,
+			Expires:  c.Expires,
+			Secure:   c.Secure,
+			HttpOnly: c.HTTPOnly,
+		}})
+	}
+	return s, nil
+}
+
+// SetCookies implements http.CookieJar
+func (s *Session) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	s.jar.SetCookies(u, cookies)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for _, c := range cookies {
+		saved := savedCookie{
+			URL:      u.String(),
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   c.Domain,
+			Path:     c.Path,
+			Expires:  c.Expires,
+			Secure:   c.Secure,
+			HTTPOnly: c.HttpOnly,
+		}
+		if c.MaxAge > 0 {
+			saved.Expires = now.Add(time.Duration(c.MaxAge) * time.Second)
+		}
+
+		key := cookieKey(u, c)
+		if c.MaxAge < 0 || (!saved.Expires.IsZero() && !saved.Expires.After(now)) {
+			delete(s.cookies, key)
+			continue
+		}
+		s.cookies[key] = saved
+	}
+}
+
+// Cookies implements http.CookieJar
+func (s *Session) Cookies(u *url.URL) []*http.Cookie {
+	return s.jar.Cookies(u)
+}
+
+// Save writes the unexpired cookies of the session to path
+func (s *Session) Save(path string) error {
+	s.mu.Lock()
+	now := time.Now()
+	cookies := make([]savedCookie, 0, len(s.cookies))
+	for _, c := range s.cookies {
+		if c.Expires.IsZero() || c.Expires.After(now) {
+			cookies = append(cookies, c)
+		}
+	}
+	s.mu.Unlock()
+
+	data, err := json.MarshalIndent(cookies, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "session-*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// cookieKey identifies the cookie c set by u the way the jar does
+func cookieKey(u *url.URL, c *http.Cookie) string {
+	domain := strings.ToLower(strings.TrimPrefix(c.Domain, "."))
+	if domain == "" {
+		domain = strings.ToLower(u.Hostname())
+	}
+	path := c.Path
+	if path == "" || path[0] != '/' {
+		path = "/"
+		if i := strings.LastIndex(u.Path, "/"); i > 0 {
+			path = u.Path[:i]
+		}
+	}
+	return c.Name + ";" + domain + ";" + path
+}
+
+// Session returns the cookie jar shared by every request of the Scraper
+func (s *Scraper) Session() *Session {
+	return s.session
+}
+
+// LoginForm describes an HTML login form submitted by Scraper.Login
+type LoginForm struct {
+	// URL is the page containing the form
+	URL string
+	// FormSelector is the CSS selector of the form
+	// Defaults to the first form with a password input
+	FormSelector string
+	// Fields sets form values such as credentials, overriding values on the page
+	Fields map[string]string
+	// SuccessSelector, if set, must match the page returned after submitting
+	// the form, e.g. a logout link
+	SuccessSelector string
+}
+
+// Login fetches the login page, fills form.Fields on top of the values already
+// in the form, including hidden inputs such as CSRF tokens, and submits it
+// The cookies set along the way are kept in the Session, so later requests
+// run logged in. Returns the page the form submission ended on
+func (s *Scraper) Login(ctx context.Context, form LoginForm) (*Document, error) {
+	// Skip the cache, CSRF tokens are only valid for a fresh page
+	resp, err := s.fetchUncached(ctx, &Request{Method: http.MethodGet, URL: form.URL})
+	if err != nil {
+		return nil, err
+	}
+	page, err := NewDocumentFromResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	selector := form.FormSelector
+	if selector == "" {
+		selector = "form:has(input[type=password])"
+	}
+	sel := page.Selection().Find(selector).First()
+	if sel.Length() == 0 {
+		return nil, &ExtractionError{URL: page.URL(), Selector: selector, Err: errors.New("login form not found")}
+	}
+
+	values := formValues(sel)
+	for name, value := range form.Fields {
+		values.Set(name, value)
+	}
+
+	action, _ := sel.Attr("action")
+	action = page.ResolveURL(action)
+	req := &Request{Method: http.MethodPost, URL: action, Header: http.Header{}, Form: values}
+	req.Header.Set("Referer", page.URL())
+
+	if method, _ := sel.Attr("method"); !strings.EqualFold(method, http.MethodPost) {
+		req.Method = http.MethodGet
+		req.URL = setQuery(action, values)
+		req.Form = nil
+	}
+
+	resp, err = s.fetchUncached(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := NewDocumentFromResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	if form.SuccessSelector != "" && doc.Selection().Find(form.SuccessSelector).Length() == 0 {
+		return nil, fmt.Errorf("login at %s: %w", form.URL, ErrLoginFailed)
+	}
+	return doc, nil
+}
+
+// fetchUncached performs req after the domain and robots.txt checks, bypassing the cache
+func (s *Scraper) fetchUncached(ctx context.Context, req *Request) (*Response, error) {
+	req, err := req.prepare()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.domainCheck(req.URL); err != nil {
+		return nil, err
+	}
+	if err := s.robotsCheck(ctx, req.URL); err != nil {
+		return nil, err
+	}
+	resp, err := s.do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return s.decodeResponse(resp)
+}
+
+// formValues returns the values a browser would submit for form, without
+// any submit button
+func formValues(form *goquery.Selection) url.Values {
+	values := url.Values{}
+
+	form.Find("input[name], select[name], textarea[name]").Each(func(_ int, field *goquery.Selection) {
+		if _, disabled := field.Attr("disabled"); disabled {
+			return
+		}
+		name, _ := field.Attr("name")
+
+		switch goquery.NodeName(field) {
+		case "textarea":
+			values.Add(name, field.Text())
+		case "select":
+			option := field.Find("option[selected]").First()
+			if option.Length() == 0 {
+				option = field.Find("option").First()
+			}
+			if option.Length() == 0 {
+				return
+			}
+			value, ok := option.Attr("value")
+			if !ok {
+				value = strings.TrimSpace(option.Text())
+			}
+			values.Add(name, value)
+		default:
+			value, _ := field.Attr("value")
+			switch strings.ToLower(field.AttrOr("type", "text")) {
+			case "submit", "button", "image", "reset", "file":
+				return
+			case "checkbox", "radio":
+				if _, checked := field.Attr("checked"); !checked {
+					return
+				}
+				if value == "" {
+					value = "on"
+				}
+			}
+			values.Add(name, value)
+		}
+	})
+
+	return values
+}
+
+// setQuery replaces the query of rawURL with values
+func setQuery(rawURL string, values url.Values) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	u.RawQuery = values.Encode()
+	return u.String()
+}
@@ -0,0 +1,233 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// newSessionServer returns a server that sets a cookie on /set and echoes it elsewhere
+func newSessionServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/set":
+			http.SetCookie(w, &http.Cookie{Name: "sid", Value: "abc", MaxAge: 3600})
+			http.SetCookie(w, &http.Cookie{Name: "temp", Value: "1"})
+		case "/redirect":
+			// Cookies set on a redirect must be kept as well
+			http.SetCookie(w, &http.Cookie{Name: "hop", Value: "2"})
+			http.Redirect(w, r, "/check", http.StatusFound)
+			return
+		case "/clear":
+			http.SetCookie(w, &http.Cookie{Name: "temp", MaxAge: -1})
+		}
+		var names []string
+		for _, cookie := range r.Cookies() {
+			names = append(names, cookie.Name+"="+cookie.Value)
+		}
+		_, _ = w.Write([]byte(strings.Join(names, ";")))
+	}))
+}
+
+// TestSession_Cookies verifies cookies persist across calls for both built-in fetchers
+func TestSession_Cookies(t *testing.T) {
+	server := newSessionServer()
+	defer server.Close()
+
+	fetchers := map[string]Fetcher{
+		"colly": NewCollyFetcher(Options{UserAgent: DefaultUserAgent}),
+		"http":  NewHTTPFetcher(nil, DefaultUserAgent),
+	}
+
+	for name, fetcher := range fetchers {
+		t.Run(name, func(t *testing.T) {
+			s := New(Options{MaxRetries: 1, Fetcher: fetcher})
+
+			steps := []struct {
+				path     string
+				expected string
+			}{
+				{"/set", ""},
+				{"/check", "sid=abc;temp=1"},
+				{"/redirect", "sid=abc;temp=1;hop=2"},
+				{"/clear", "sid=abc;temp=1;hop=2"},
+				{"/check", "sid=abc;hop=2"},
+			}
+			for _, step := range steps {
+				html, err := s.ScrapeHTML(server.URL + step.path)
+				if err != nil {
+					t.Fatalf("ScrapeHTML(%s) error = %v", step.path, err)
+				}
+				if html != step.expected {
+					t.Errorf("Cookies sent to %s = %q, want %q", step.path, html, step.expected)
+				}
+			}
+		})
+	}
+}
+
+// TestSession_SaveLoad verifies saved sessions restore unexpired cookies
+func TestSession_SaveLoad(t *testing.T) {
+	server := newSessionServer()
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "session.json")
+	session, err := LoadSession(path)
+	if err != nil {
+		t.Fatalf("LoadSession() of a missing file error = %v", err)
+	}
+
+	s := New(Options{MaxRetries: 1, Session: session})
+	for _, page := range []string{"/set", "/clear"} {
+		if _, err := s.ScrapeHTML(server.URL + page); err != nil {
+			t.Fatalf("ScrapeHTML(%s) error = %v", page, err)
+		}
+	}
+	if err := s.Session().Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := LoadSession(path)
+	if err != nil {
+		t.Fatalf("LoadSession() error = %v", err)
+	}
+	html, err := New(Options{MaxRetries: 1, Session: loaded}).ScrapeHTML(server.URL + "/check")
+	if err != nil {
+		t.Fatalf("ScrapeHTML() error = %v", err)
+	}
+	if html != "sid=abc" {
+		t.Errorf("Cookies sent with loaded session = %q, want %q", html, "sid=abc")
+	}
+}
+
+// TestScraper_Login verifies the login flow and paginating within the session
+func TestScraper_Login(t *testing.T) {
+	const loginPage = `<html><body>
+		<form action="/search"><input name="q"></form>
+		<form method="post" action="/login">
+			<input type="hidden" name="csrf" value="token-1">
+			<input name="username">
+			<input type="password" name="password">
+			<input type="checkbox" name="remember" checked>
+			<input type="submit" name="go" value="Sign in">
+		</form>
+	</body></html>`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		loggedIn := false
+		if cookie, err := r.Cookie("session"); err == nil && cookie.Value == "alice" {
+			loggedIn = true
+		}
+
+		switch r.URL.Path {
+		case "/login":
+			if r.Method == http.MethodGet {
+				http.SetCookie(w, &http.Cookie{Name: "csrf", Value: "token-1"})
+				_, _ = w.Write([]byte(loginPage))
+				return
+			}
+			csrf, err := r.Cookie("csrf")
+			if err != nil || r.PostFormValue("csrf") != csrf.Value || r.PostFormValue("remember") != "on" ||
+				r.PostFormValue("go") != "" || r.PostFormValue("password") != "secret" {
+				_, _ = w.Write([]byte(`<p class="error">Invalid login</p>`))
+				return
+			}
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: r.PostFormValue("username")})
+			http.Redirect(w, r, "/account", http.StatusSeeOther)
+		case "/account":
+			if loggedIn {
+				_, _ = w.Write([]byte(`<a class="logout" href="/logout">Log out</a>`))
+			}
+		case "/items", "/items/2":
+			if !loggedIn {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			if r.URL.Path == "/items" {
+				_, _ = w.Write([]byte(`<div class="item">1</div><a class="next" href="/items/2">Next</a>`))
+				return
+			}
+			_, _ = w.Write([]byte(`<div class="item">2</div>`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	t.Run("Success", func(t *testing.T) {
+		s := New(Options{MaxRetries: 1})
+		doc, err := s.Login(context.Background(), LoginForm{
+			URL:             server.URL + "/login",
+			Fields:          map[string]string{"username": "alice", "password": "secret"},
+			SuccessSelector: "a.logout",
+		})
+		if err != nil {
+			t.Fatalf("Login() error = %v", err)
+		}
+		if doc.URL() != server.URL+"/account" {
+			t.Errorf("Login() ended on %s, want /account", doc.URL())
+		}
+
+		resultsChan, err := s.ScrapePaginated(server.URL+"/items", "div.item", PaginationConfig{NextPageSelector: "a.next[href]"})
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		data := collectData(t, resultsChan)
+		expected := []string{`<div class="item">1</div>`, `<div class="item">2</div>`}
+		if !equalStrings(data, expected) {
+			t.Errorf("Results = %v, want %v", data, expected)
+		}
+	})
+
+	t.Run("Wrong password", func(t *testing.T) {
+		s := New(Options{MaxRetries: 1})
+		_, err := s.Login(context.Background(), LoginForm{
+			URL:             server.URL + "/login",
+			Fields:          map[string]string{"username": "alice", "password": "wrong"},
+			SuccessSelector: "a.logout",
+		})
+		if !errors.Is(err, ErrLoginFailed) {
+			t.Errorf("Login() error = %v, want ErrLoginFailed", err)
+		}
+	})
+
+	t.Run("Missing form", func(t *testing.T) {
+		s := New(Options{MaxRetries: 1})
+		_, err := s.Login(context.Background(), LoginForm{URL: server.URL + "/login", FormSelector: "form#signin"})
+		var extractionErr *ExtractionError
+		if !errors.As(err, &extractionErr) {
+			t.Errorf("Login() error = %v, want *ExtractionError", err)
+		}
+	})
+}
+
+// TestFormValues verifies the values submitted for each kind of form field
+func TestFormValues(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(`<form>
+		<input name="text" value="a b">
+		<input name="untyped">
+		<input type="checkbox" name="off">
+		<input type="radio" name="choice" value="x">
+		<input type="radio" name="choice" value="y" checked>
+		<input name="disabled" value="1" disabled>
+		<input type="submit" name="submit" value="Go">
+		<select name="size"><option value="s">S</option><option selected>M</option></select>
+		<select name="color"><option value="red">Red</option></select>
+		<textarea name="notes">hello</textarea>
+	</form>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := formValues(doc.Find("form")).Encode()
+	expected := "choice=y&color=red&notes=hello&size=M&text=a+b&untyped="
+	if got != expected {
+		t.Errorf("formValues() = %q, want %q", got, expected)
+	}
+}
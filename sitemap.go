@@ -0,0 +1,223 @@
+package scraper
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SitemapEntry is a single URL listed in a sitemap
+type SitemapEntry struct {
+	// URL is the <loc> of the page
+	URL string
+	// LastMod is the <lastmod> of the page, zero if missing
+	LastMod time.Time
+	// ChangeFreq is the <changefreq> of the page, e.g. "daily", empty if missing
+	ChangeFreq string
+	// Priority is the <priority> of the page, 0.5 if missing
+	Priority float64
+	// Sitemap is the URL of the sitemap listing the page
+	Sitemap string
+	// Err is set if a sitemap could not be fetched or parsed
+	Err error
+}
+
+// SitemapConfig holds configuration for sitemap ingestion
+type SitemapConfig struct {
+	// ModifiedSince skips entries last modified before this time, including
+	// whole sitemaps of an index. Entries without lastmod are kept
+	ModifiedSince time.Time
+	// SkipUndated also skips entries without lastmod when ModifiedSince is set
+	SkipUndated bool
+}
+
+// sitemapDocument is either a urlset or a sitemapindex
+type sitemapDocument struct {
+	XMLName  xml.Name
+	URLs     []sitemapItem `xml:"url"`
+	Sitemaps []sitemapItem `xml:"sitemap"`
+}
+
+type sitemapItem struct {
+	Loc        string `xml:"loc"`
+	LastMod    string `xml:"lastmod"`
+	ChangeFreq string `xml:"changefreq"`
+	Priority   string `xml:"priority"`
+}
+
+// sitemapTimeLayouts are the W3C datetime forms allowed in <lastmod>
+var sitemapTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+	"2006-01",
+	"2006",
+}
+
+// parseSitemapTime parses a <lastmod> value, returning the zero time if invalid
+func parseSitemapTime(value string) time.Time {
+	value = strings.TrimSpace(value)
+	for _, layout := range sitemapTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// keep reports whether an entry modified at lastMod passes the filter
+func (c SitemapConfig) keep(lastMod time.Time) bool {
+	if c.ModifiedSince.IsZero() {
+		return true
+	}
+	if lastMod.IsZero() {
+		return !c.SkipUndated
+	}
+	return !lastMod.Before(c.ModifiedSince)
+}
+
+// decodeSitemap parses a sitemap body, decompressing gzip if needed
+// Bodies not yet decoded to UTF-8, such as compressed ones, are decoded here
+// Plain text sitemaps with one URL per line are supported as well
+func decodeSitemap(body []byte, decoded bool) (*sitemapDocument, error) {
+	if len(body) > 2 && body[0] == 0x1f && body[1] == 0x8b {
+		zr, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		if body, err = io.ReadAll(zr); err != nil {
+			return nil, fmt.Errorf("failed to decompress sitemap: %w", err)
+		}
+		decoded = false
+	}
+	if !decoded {
+		var err error
+		if body, _, err = DecodeToUTF8(body, "", ""); err != nil {
+			return nil, err
+		}
+	}
+
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) > 0 && trimmed[0] != '<' {
+		doc := &sitemapDocument{XMLName: xml.Name{Local: "urlset"}}
+		scanner := bufio.NewScanner(bytes.NewReader(trimmed))
+		for scanner.Scan() {
+			if line := strings.TrimSpace(scanner.Text()); line != "" {
+				doc.URLs = append(doc.URLs, sitemapItem{Loc: line})
+			}
+		}
+		return doc, scanner.Err()
+	}
+
+	// The body is UTF-8 already, whatever the XML declaration says
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	decoder.CharsetReader = func(_ string, r io.Reader) (io.Reader, error) {
+		return r, nil
+	}
+	var doc sitemapDocument
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to parse sitemap: %w", err)
+	}
+	if doc.XMLName.Local != "urlset" && doc.XMLName.Local != "sitemapindex" {
+		return nil, fmt.Errorf("unexpected sitemap root element <%s>", doc.XMLName.Local)
+	}
+	return &doc, nil
+}
+
+// ScrapeSitemap streams the entries of a sitemap, following sitemap indexes recursively
+// Gzip-compressed sitemaps are decompressed automatically
+func (s *Scraper) ScrapeSitemap(url string, config SitemapConfig) (<-chan SitemapEntry, error) {
+	return s.ScrapeSitemapContext(context.Background(), url, config)
+}
+
+// ScrapeSitemapContext is like ScrapeSitemap but stops early and closes the channel when ctx is cancelled
+func (s *Scraper) ScrapeSitemapContext(ctx context.Context, url string, config SitemapConfig) (<-chan SitemapEntry, error) {
+	if url == "" {
+		return nil, fmt.Errorf("sitemap URL is required")
+	}
+
+	entries := make(chan SitemapEntry)
+	go func() {
+		defer close(entries)
+		s.scrapeSitemap(ctx, url, config, make(map[string]bool), entries)
+	}()
+	return entries, nil
+}
+
+// scrapeSitemap sends the entries of the sitemap at url, descending into indexes
+// visited guards against indexes listing each other
+// Returns false if ctx was cancelled
+func (s *Scraper) scrapeSitemap(ctx context.Context, url string, config SitemapConfig, visited map[string]bool, entries chan<- SitemapEntry) bool {
+	visited[frontierKey(url)] = true
+
+	emit := func(entry SitemapEntry) bool {
+		select {
+		case entries <- entry:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	resp, err := s.FetchContext(ctx, url)
+	if err != nil {
+		if ctx.Err() != nil {
+			return false
+		}
+		return emit(SitemapEntry{Sitemap: url, Err: fmt.Errorf("failed to fetch sitemap %s: %w", url, err)})
+	}
+
+	// Only text responses were decoded, .xml.gz sitemaps may arrive decompressed
+	doc, err := decodeSitemap(resp.Body, resp.Charset != "")
+	if err != nil {
+		return emit(SitemapEntry{Sitemap: url, Err: fmt.Errorf("sitemap %s: %w", url, err)})
+	}
+
+	for _, item := range doc.Sitemaps {
+		if strings.TrimSpace(item.Loc) == "" {
+			continue
+		}
+		loc := GetFullURL(resp.URL, item.Loc)
+		// Undated sitemaps may still list changed pages
+		lastMod := parseSitemapTime(item.LastMod)
+		if visited[frontierKey(loc)] || (!lastMod.IsZero() && !config.keep(lastMod)) {
+			continue
+		}
+		if !s.scrapeSitemap(ctx, loc, config, visited, entries) {
+			return false
+		}
+	}
+
+	for _, item := range doc.URLs {
+		if strings.TrimSpace(item.Loc) == "" {
+			continue
+		}
+		entry := SitemapEntry{
+			URL:        GetFullURL(resp.URL, item.Loc),
+			LastMod:    parseSitemapTime(item.LastMod),
+			ChangeFreq: strings.ToLower(strings.TrimSpace(item.ChangeFreq)),
+			Priority:   0.5,
+			Sitemap:    url,
+		}
+		if priority, err := strconv.ParseFloat(strings.TrimSpace(item.Priority), 64); err == nil {
+			entry.Priority = priority
+		}
+		if !config.keep(entry.LastMod) {
+			continue
+		}
+		if !emit(entry) {
+			return false
+		}
+	}
+
+	return true
+}
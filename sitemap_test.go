@@ -0,0 +1,219 @@
+package scraper
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+func newSitemapServer() *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		base := server.URL
+		switch r.URL.Path {
+		case "/sitemap.xml":
+			_, _ = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+	<sitemap><loc>` + base + `/pages.xml</loc><lastmod>2024-03-01</lastmod></sitemap>
+	<sitemap><loc>/products.gz</loc></sitemap>
+	<sitemap><loc>` + base + `/old.xml</loc><lastmod>2020-01-01</lastmod></sitemap>
+	<sitemap><loc>` + base + `/sitemap.xml</loc></sitemap>
+</sitemapindex>`))
+		case "/pages.xml":
+			_, _ = w.Write([]byte(`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+	<url><loc>` + base + `/about</loc><lastmod>2024-03-01T10:00:00+00:00</lastmod><changefreq>Monthly</changefreq><priority>0.8</priority></url>
+	<url><loc>` + base + `/contact</loc></url>
+</urlset>`))
+		case "/products.gz":
+			var buf bytes.Buffer
+			zw := gzip.NewWriter(&buf)
+			zw.Write([]byte(`<urlset>
+	<url><loc>` + base + `/p/1</loc><lastmod>2024-02-01</lastmod></url>
+	<url><loc>` + base + `/p/2</loc><lastmod>2023-01-01</lastmod></url>
+</urlset>`))
+			zw.Close()
+			w.Header().Set("Content-Type", "application/octet-stream")
+			_, _ = w.Write(buf.Bytes())
+		case "/old.xml":
+			_, _ = w.Write([]byte(`<urlset><url><loc>` + base + `/old</loc><lastmod>2020-01-01</lastmod></url></urlset>`))
+		case "/links.txt":
+			_, _ = w.Write([]byte(base + "/a\n\n" + base + "/b\n"))
+		case "/broken.xml":
+			_, _ = w.Write([]byte(`<html><body>not a sitemap</body></html>`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return server
+}
+
+// TestScrapeSitemap verifies indexes, gzip, metadata and lastmod filtering
+func TestScrapeSitemap(t *testing.T) {
+	server := newSitemapServer()
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		url      string
+		config   SitemapConfig
+		expected []string
+	}{
+		{
+			"Index",
+			"/sitemap.xml",
+			SitemapConfig{},
+			[]string{"/about", "/contact", "/old", "/p/1", "/p/2"},
+		},
+		{
+			"Modified since",
+			"/sitemap.xml",
+			SitemapConfig{ModifiedSince: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+			[]string{"/about", "/contact", "/p/1"},
+		},
+		{
+			"Skip undated",
+			"/sitemap.xml",
+			SitemapConfig{ModifiedSince: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), SkipUndated: true},
+			[]string{"/about", "/p/1"},
+		},
+		{
+			"Text sitemap",
+			"/links.txt",
+			SitemapConfig{},
+			[]string{"/a", "/b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New(Options{MaxRetries: 1})
+			entries, err := s.ScrapeSitemap(server.URL+tt.url, tt.config)
+			if err != nil {
+				t.Fatalf("ScrapeSitemap() error = %v", err)
+			}
+
+			var urls []string
+			for entry := range entries {
+				if entry.Err != nil {
+					t.Errorf("Received error from channel: %v", entry.Err)
+					continue
+				}
+				urls = append(urls, strings.TrimPrefix(entry.URL, server.URL))
+			}
+			sort.Strings(urls)
+
+			if !equalStrings(urls, tt.expected) {
+				t.Errorf("ScrapeSitemap() = %v, want %v", urls, tt.expected)
+			}
+		})
+	}
+}
+
+// TestScrapeSitemap_Metadata verifies lastmod, changefreq and priority are exposed
+func TestScrapeSitemap_Metadata(t *testing.T) {
+	server := newSitemapServer()
+	defer server.Close()
+
+	s := New(Options{MaxRetries: 1})
+	entries, _ := s.ScrapeSitemap(server.URL+"/pages.xml", SitemapConfig{})
+
+	var got []SitemapEntry
+	for entry := range entries {
+		got = append(got, entry)
+	}
+	if len(got) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(got))
+	}
+
+	about := got[0]
+	if !about.LastMod.Equal(time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)) || about.ChangeFreq != "monthly" || about.Priority != 0.8 {
+		t.Errorf("Unexpected metadata: %+v", about)
+	}
+	if about.Sitemap != server.URL+"/pages.xml" {
+		t.Errorf("Sitemap = %q", about.Sitemap)
+	}
+
+	contact := got[1]
+	if !contact.LastMod.IsZero() || contact.ChangeFreq != "" || contact.Priority != 0.5 {
+		t.Errorf("Expected defaults for missing metadata, got %+v", contact)
+	}
+}
+
+// TestScrapeSitemap_Charset verifies sitemaps declaring a legacy encoding are
+// parsed, plain and gzip-compressed
+func TestScrapeSitemap_Charset(t *testing.T) {
+	sitemap := func(base string) []byte {
+		return encode(t, charmap.ISO8859_1, `<?xml version="1.0" encoding="ISO-8859-1"?>
+<urlset><url><loc>`+base+`/café</loc></url></urlset>`)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		base := "http://" + r.Host
+		if r.URL.Path == "/sitemap.xml.gz" {
+			var buf bytes.Buffer
+			zw := gzip.NewWriter(&buf)
+			_, _ = zw.Write(sitemap(base + "/gz"))
+			_ = zw.Close()
+			w.Header().Set("Content-Type", "application/gzip")
+			_, _ = w.Write(buf.Bytes())
+			return
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		_, _ = w.Write(sitemap(base))
+	}))
+	defer server.Close()
+
+	s := New(Options{MaxRetries: 1})
+	for path, expected := range map[string]string{
+		"/sitemap.xml":    server.URL + "/caf%C3%A9",
+		"/sitemap.xml.gz": server.URL + "/gz/caf%C3%A9",
+	} {
+		entries, err := s.ScrapeSitemap(server.URL+path, SitemapConfig{})
+		if err != nil {
+			t.Fatalf("ScrapeSitemap() error = %v", err)
+		}
+		var got []string
+		for entry := range entries {
+			if entry.Err != nil {
+				t.Fatalf("Received error from channel: %v", entry.Err)
+			}
+			got = append(got, entry.URL)
+		}
+		if len(got) != 1 || got[0] != expected {
+			t.Errorf("Entries of %s = %v, want [%s]", path, got, expected)
+		}
+	}
+}
+
+// TestScrapeSitemap_Errors verifies fetch and parse failures are reported on the channel
+func TestScrapeSitemap_Errors(t *testing.T) {
+	server := newSitemapServer()
+	defer server.Close()
+
+	s := New(Options{MaxRetries: 1})
+	for _, path := range []string{"/missing.xml", "/broken.xml"} {
+		entries, err := s.ScrapeSitemap(server.URL+path, SitemapConfig{})
+		if err != nil {
+			t.Fatalf("ScrapeSitemap() error = %v", err)
+		}
+		var errs int
+		for entry := range entries {
+			if entry.Err != nil {
+				errs++
+			}
+		}
+		if errs != 1 {
+			t.Errorf("Expected 1 error for %s, got %d", path, errs)
+		}
+	}
+
+	if _, err := s.ScrapeSitemap("", SitemapConfig{}); err == nil {
+		t.Error("Expected error for empty URL")
+	}
+}
@@ -0,0 +1,498 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Sources of an Entity
+const (
+	SourceJSONLD    = "json-ld"
+	SourceMicrodata = "microdata"
+	SourceRDFa      = "rdfa"
+)
+
+// Entity is a schema.org style item embedded in a page as JSON-LD, microdata
+// or RDFa-lite
+type Entity struct {
+	// Types holds the @type, itemtype or typeof values, e.g. "Product" or
+	// "https://schema.org/Product"
+	Types []string
+	// ID is the @id, itemid or resource of the entity, if any
+	ID string
+	// Properties maps property names to their values in document order
+	// Values are strings, float64, bool or nil for JSON-LD scalars and *Entity
+	// for nested items. Microdata and RDFa values are always strings or *Entity
+	Properties map[string][]any
+	// Source is SourceJSONLD, SourceMicrodata or SourceRDFa
+	Source string
+}
+
+// newEntity returns an empty entity of source
+func newEntity(source string) *Entity {
+	return &Entity{Properties: make(map[string][]any), Source: source}
+}
+
+// add appends value to the property name
+func (e *Entity) add(name string, value any) {
+	e.Properties[name] = append(e.Properties[name], value)
+}
+
+// Is reports whether the entity has type typ, ignoring the vocabulary, so
+// "Product" matches "https://schema.org/Product" and "schema:Product"
+func (e *Entity) Is(typ string) bool {
+	for _, t := range e.Types {
+		if t == typ || strings.HasSuffix(t, "/"+typ) || strings.HasSuffix(t, "#"+typ) || strings.HasSuffix(t, ":"+typ) {
+			return true
+		}
+	}
+	return false
+}
+
+// Value returns the first value of the property name, nil if absent
+func (e *Entity) Value(name string) any {
+	if values := e.Properties[name]; len(values) > 0 {
+		return values[0]
+	}
+	return nil
+}
+
+// Text returns the first value of the property name as a string
+// Returns empty string if the property is absent or holds an entity
+func (e *Entity) Text(name string) string {
+	switch v := e.Value(name).(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return ""
+}
+
+// Entity returns the first nested entity of the property name, nil if none
+func (e *Entity) Entity(name string) *Entity {
+	for _, v := range e.Properties[name] {
+		if entity, ok := v.(*Entity); ok {
+			return entity
+		}
+	}
+	return nil
+}
+
+// MarshalJSON encodes the entity as a JSON-LD style object
+// Properties with a single value are encoded as that value, others as arrays
+func (e *Entity) MarshalJSON() ([]byte, error) {
+	obj := make(map[string]any, len(e.Properties)+2)
+	for name, values := range e.Properties {
+		if len(values) == 1 {
+			obj[name] = values[0]
+		} else {
+			obj[name] = values
+		}
+	}
+	switch len(e.Types) {
+	case 0:
+	case 1:
+		obj["@type"] = e.Types[0]
+	default:
+		obj["@type"] = e.Types
+	}
+	if e.ID != "" {
+		obj["@id"] = e.ID
+	}
+	return json.Marshal(obj)
+}
+
+// Decode stores the entity in the value pointed to by v using encoding/json,
+// as encoded by MarshalJSON
+// Microdata and RDFa values are strings, use the ",string" option for numbers
+func (e *Entity) Decode(v any) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// MetaTags maps meta tag names, e.g. "og:title", to their values in document order
+type MetaTags map[string][]string
+
+// Get returns the first value of the tag name, empty string if absent
+func (m MetaTags) Get(name string) string {
+	if values := m[name]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// StructuredData holds the machine-readable data embedded in a page
+type StructuredData struct {
+	// JSONLD holds the entities of every application/ld+json script, with
+	// @graph nodes as separate entities
+	JSONLD []*Entity `json:"json_ld"`
+	// Microdata holds the top-level itemscope items
+	Microdata []*Entity `json:"microdata"`
+	// RDFa holds the top-level typeof items
+	RDFa []*Entity `json:"rdfa"`
+	// OpenGraph holds the <meta property> tags, e.g. "og:title" or "article:author"
+	OpenGraph MetaTags `json:"open_graph"`
+	// Twitter holds the twitter card tags, e.g. "twitter:card"
+	Twitter MetaTags `json:"twitter"`
+}
+
+// Entities returns the top-level JSON-LD, microdata and RDFa entities, in that order
+func (d *StructuredData) Entities() []*Entity {
+	entities := make([]*Entity, 0, len(d.JSONLD)+len(d.Microdata)+len(d.RDFa))
+	entities = append(entities, d.JSONLD...)
+	entities = append(entities, d.Microdata...)
+	return append(entities, d.RDFa...)
+}
+
+// All returns every entity of type typ, including nested ones such as the
+// Product in the mainEntity of a WebPage
+func (d *StructuredData) All(typ string) []*Entity {
+	var found []*Entity
+	var walk func(e *Entity)
+	walk = func(e *Entity) {
+		if e.Is(typ) {
+			found = append(found, e)
+		}
+		for _, values := range e.Properties {
+			for _, v := range values {
+				if nested, ok := v.(*Entity); ok {
+					walk(nested)
+				}
+			}
+		}
+	}
+	for _, e := range d.Entities() {
+		walk(e)
+	}
+	return found
+}
+
+// First returns the first entity of type typ, e.g. "Product" or "Article"
+// Returns nil if the page has none
+func (d *StructuredData) First(typ string) *Entity {
+	if found := d.All(typ); len(found) > 0 {
+		return found[0]
+	}
+	return nil
+}
+
+// ByID returns the top-level entity with the given @id, used to follow JSON-LD
+// references such as {"@id": "#organization"}. Returns nil if none matches
+func (d *StructuredData) ByID(id string) *Entity {
+	for _, e := range d.Entities() {
+		if e.ID == id {
+			return e
+		}
+	}
+	return nil
+}
+
+// GetStructuredData extracts the structured data embedded in HTML text
+func GetStructuredData(htmlText string) (*StructuredData, error) {
+	doc, err := NewDocument(htmlText)
+	if err != nil {
+		return nil, err
+	}
+	return doc.StructuredData(), nil
+}
+
+// StructuredData extracts the JSON-LD, microdata, RDFa-lite and meta tag data
+// of the document. Links in microdata and RDFa are resolved against the base URL
+// Malformed JSON-LD scripts are skipped
+func (d *Document) StructuredData() *StructuredData {
+	root := d.sel.Closest("html")
+	if root.Length() == 0 {
+		root = d.sel
+	}
+
+	data := &StructuredData{
+		JSONLD:    d.jsonLDEntities(),
+		Microdata: d.microdataItems(root),
+		RDFa:      d.rdfaItems(),
+		OpenGraph: MetaTags{},
+		Twitter:   MetaTags{},
+	}
+
+	d.sel.Find("meta[content]").Each(func(_ int, meta *goquery.Selection) {
+		content, _ := meta.Attr("content")
+		property := strings.ToLower(strings.TrimSpace(meta.AttrOr("property", "")))
+		name := strings.ToLower(strings.TrimSpace(meta.AttrOr("name", "")))
+		switch {
+		case strings.HasPrefix(property, "twitter:"):
+			data.Twitter[property] = append(data.Twitter[property], content)
+		case strings.HasPrefix(name, "twitter:"):
+			data.Twitter[name] = append(data.Twitter[name], content)
+		case strings.Contains(property, ":"):
+			data.OpenGraph[property] = append(data.OpenGraph[property], content)
+		case strings.HasPrefix(name, "og:"):
+			// A common mistake, browsers and crawlers accept it
+			data.OpenGraph[name] = append(data.OpenGraph[name], content)
+		}
+	})
+
+	return data
+}
+
+// FirstEntity returns the first entity of type typ in the structured data of
+// the document, nil if there is none
+func (d *Document) FirstEntity(typ string) *Entity {
+	return d.StructuredData().First(typ)
+}
+
+// jsonLDEntities parses every JSON-LD script of the document
+func (d *Document) jsonLDEntities() []*Entity {
+	var entities []*Entity
+	d.sel.Find(`script[type="application/ld+json"]`).Each(func(_ int, script *goquery.Selection) {
+		text := strings.TrimSpace(script.Text())
+		// Some sites wrap the JSON in comments or CDATA sections for old browsers
+		for _, wrapper := range [][2]string{{"<!--", "-->"}, {"//<![CDATA[", "//]]>"}, {"<![CDATA[", "]]>"}} {
+			if strings.HasPrefix(text, wrapper[0]) && strings.HasSuffix(text, wrapper[1]) {
+				text = strings.TrimSpace(text[len(wrapper[0]) : len(text)-len(wrapper[1])])
+			}
+		}
+		text = strings.TrimSuffix(text, ";")
+
+		var v any
+		if err := json.Unmarshal([]byte(text), &v); err != nil {
+			return
+		}
+		entities = append(entities, jsonLDNodes(v)...)
+	})
+	return entities
+}
+
+// jsonLDNodes returns the entities of a parsed JSON-LD document, flattening
+// arrays and @graph
+func jsonLDNodes(v any) []*Entity {
+	switch v := v.(type) {
+	case []any:
+		var entities []*Entity
+		for _, item := range v {
+			entities = append(entities, jsonLDNodes(item)...)
+		}
+		return entities
+	case map[string]any:
+		if graph, ok := v["@graph"]; ok {
+			return jsonLDNodes(graph)
+		}
+		return []*Entity{jsonLDEntity(v)}
+	}
+	return nil
+}
+
+// jsonLDEntity converts a JSON-LD node object into an Entity
+func jsonLDEntity(obj map[string]any) *Entity {
+	e := newEntity(SourceJSONLD)
+	for key, value := range obj {
+		switch key {
+		case "@type":
+			e.Types = append(e.Types, jsonLDStrings(value)...)
+		case "@id":
+			e.ID, _ = value.(string)
+		case "@context":
+		default:
+			values, ok := value.([]any)
+			if !ok {
+				values = []any{value}
+			}
+			for _, item := range values {
+				e.add(key, jsonLDValue(item))
+			}
+		}
+	}
+	return e
+}
+
+// jsonLDValue converts a JSON-LD property value, unwrapping value objects
+func jsonLDValue(v any) any {
+	obj, ok := v.(map[string]any)
+	if !ok {
+		return v
+	}
+	if value, ok := obj["@value"]; ok {
+		return value
+	}
+	return jsonLDEntity(obj)
+}
+
+// jsonLDStrings returns a string or an array of strings as a slice
+func jsonLDStrings(v any) []string {
+	switch v := v.(type) {
+	case string:
+		return []string{v}
+	case []any:
+		var values []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// microdataItems returns the top-level microdata items of the document
+func (d *Document) microdataItems(root *goquery.Selection) []*Entity {
+	var items []*Entity
+	d.sel.Find("[itemscope]").Not("[itemprop]").Each(func(_ int, el *goquery.Selection) {
+		items = append(items, d.microdataItem(el, root))
+	})
+	return items
+}
+
+// microdataItem builds the item of an itemscope element, following itemref
+func (d *Document) microdataItem(el, root *goquery.Selection) *Entity {
+	item := newEntity(SourceMicrodata)
+	item.Types = strings.Fields(el.AttrOr("itemtype", ""))
+	item.ID = el.AttrOr("itemid", "")
+
+	isScope := func(sel *goquery.Selection) bool {
+		_, ok := sel.Attr("itemscope")
+		return ok
+	}
+	value := func(sel *goquery.Selection) any {
+		if isScope(sel) {
+			return d.microdataItem(sel, root)
+		}
+		return d.microdataValue(sel)
+	}
+
+	d.collectProperties(el, "itemprop", isScope, value, item)
+	for _, id := range strings.Fields(el.AttrOr("itemref", "")) {
+		root.Find("[id]").FilterFunction(func(_ int, ref *goquery.Selection) bool {
+			return ref.AttrOr("id", "") == id
+		}).First().Each(func(_ int, ref *goquery.Selection) {
+			// The referenced element may itself be a property of the item
+			for _, name := range strings.Fields(ref.AttrOr("itemprop", "")) {
+				item.add(name, value(ref))
+			}
+			if !isScope(ref) {
+				d.collectProperties(ref, "itemprop", isScope, value, item)
+			}
+		})
+	}
+	return item
+}
+
+// microdataValue returns the value of a microdata property element
+func (d *Document) microdataValue(el *goquery.Selection) string {
+	switch goquery.NodeName(el) {
+	case "meta":
+		return el.AttrOr("content", "")
+	case "audio", "embed", "iframe", "img", "source", "track", "video":
+		return d.resolveAttr(el, "src")
+	case "a", "area", "link":
+		return d.resolveAttr(el, "href")
+	case "object":
+		return d.resolveAttr(el, "data")
+	case "data", "meter":
+		return el.AttrOr("value", "")
+	case "time":
+		if datetime, ok := el.Attr("datetime"); ok {
+			return datetime
+		}
+	}
+	return strings.TrimSpace(el.Text())
+}
+
+// rdfaItems returns the top-level RDFa-lite items of the document
+func (d *Document) rdfaItems() []*Entity {
+	var items []*Entity
+	d.sel.Find("[typeof]").Not("[property]").Each(func(_ int, el *goquery.Selection) {
+		items = append(items, d.rdfaItem(el))
+	})
+	return items
+}
+
+// rdfaItem builds the item of a typeof element
+func (d *Document) rdfaItem(el *goquery.Selection) *Entity {
+	item := newEntity(SourceRDFa)
+	item.Types = strings.Fields(el.AttrOr("typeof", ""))
+	item.ID = el.AttrOr("resource", "")
+
+	isScope := func(sel *goquery.Selection) bool {
+		_, ok := sel.Attr("typeof")
+		return ok
+	}
+	value := func(sel *goquery.Selection) any {
+		if isScope(sel) {
+			return d.rdfaItem(sel)
+		}
+		return d.rdfaValue(sel)
+	}
+
+	d.collectProperties(el, "property", isScope, value, item)
+	return item
+}
+
+// rdfaValue returns the value of an RDFa-lite property element
+func (d *Document) rdfaValue(el *goquery.Selection) string {
+	if content, ok := el.Attr("content"); ok {
+		return content
+	}
+	if resource, ok := el.Attr("resource"); ok {
+		return d.ResolveURL(resource)
+	}
+	for _, attr := range []string{"href", "src"} {
+		if _, ok := el.Attr(attr); ok {
+			return d.resolveAttr(el, attr)
+		}
+	}
+	if datetime, ok := el.Attr("datetime"); ok && goquery.NodeName(el) == "time" {
+		return datetime
+	}
+	return strings.TrimSpace(el.Text())
+}
+
+// collectProperties adds the properties found below el to item, without
+// descending into nested items, which become property values themselves
+func (d *Document) collectProperties(el *goquery.Selection, attr string, isScope func(*goquery.Selection) bool,
+	value func(*goquery.Selection) any, item *Entity) {
+	el.Children().Each(func(_ int, child *goquery.Selection) {
+		for _, name := range strings.Fields(child.AttrOr(attr, "")) {
+			item.add(name, value(child))
+		}
+		if !isScope(child) {
+			d.collectProperties(child, attr, isScope, value, item)
+		}
+	})
+}
+
+// resolveAttr returns the link in attribute attr of el resolved against the base URL
+func (d *Document) resolveAttr(el *goquery.Selection, attr string) string {
+	link := strings.TrimSpace(el.AttrOr(attr, ""))
+	if link == "" {
+		return ""
+	}
+	return d.ResolveURL(link)
+}
+
+// ScrapeStructuredData fetches url and extracts its structured data
+func (s *Scraper) ScrapeStructuredData(url string) (*StructuredData, error) {
+	return s.ScrapeStructuredDataContext(context.Background(), url)
+}
+
+// ScrapeStructuredDataContext is like ScrapeStructuredData but honours ctx cancellation
+func (s *Scraper) ScrapeStructuredDataContext(ctx context.Context, url string) (*StructuredData, error) {
+	return s.ScrapeStructuredDataRequest(ctx, &Request{URL: url})
+}
+
+// ScrapeStructuredDataRequest is like ScrapeStructuredDataContext for a custom request
+func (s *Scraper) ScrapeStructuredDataRequest(ctx context.Context, req *Request) (*StructuredData, error) {
+	doc, err := s.ScrapeDocumentRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return doc.StructuredData(), nil
+}
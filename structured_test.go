@@ -0,0 +1,241 @@
+package scraper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+const structuredPage = `<html><head>
+	<base href="https://shop.example.com/catalog/">
+	<meta property="og:title" content="Blue Widget">
+	<meta property="og:image" content="https://shop.example.com/1.jpg">
+	<meta property="og:image" content="https://shop.example.com/2.jpg">
+	<meta property="article:author" content="Alice">
+	<meta name="og:site_name" content="Example Shop">
+	<meta name="twitter:card" content="summary_large_image">
+	<meta property="twitter:site" content="@example">
+	<meta name="description" content="Not structured">
+	<script type="application/ld+json">
+	{
+		"@context": "https://schema.org",
+		"@graph": [
+			{"@type": "Organization", "@id": "#org", "name": "Example Shop"},
+			{
+				"@type": "WebPage",
+				"publisher": {"@id": "#org"},
+				"mainEntity": {
+					"@type": "Product",
+					"name": "Blue Widget",
+					"sku": 1234,
+					"image": ["a.jpg", "b.jpg"],
+					"description": {"@value": "A widget", "@language": "en"},
+					"offers": {"@type": "Offer", "price": 19.99, "priceCurrency": "USD", "availability": "https://schema.org/InStock"}
+				}
+			}
+		]
+	}
+	</script>
+	<script type="application/ld+json">{"@type": "Broken",</script>
+	<script type="application/ld+json"><!-- {"@type": ["Article", "NewsArticle"], "headline": "Launch"}; --></script>
+</head><body>
+	<div itemscope itemtype="https://schema.org/Product" itemid="urn:sku:42" itemref="rating">
+		<h1 itemprop="name">Red Widget</h1>
+		<img itemprop="image" src="red.jpg">
+		<a itemprop="url" href="/products/red">Details</a>
+		<div itemprop="offers" itemscope itemtype="https://schema.org/Offer">
+			<meta itemprop="priceCurrency" content="EUR">
+			<span itemprop="price">9.50</span>
+			<link itemprop="availability" href="https://schema.org/OutOfStock">
+		</div>
+		<div>
+			<span itemprop="brand keywords">Acme</span>
+		</div>
+	</div>
+	<div id="rating" itemprop="aggregateRating" itemscope itemtype="https://schema.org/AggregateRating">
+		<span itemprop="ratingValue">4.5</span>
+	</div>
+	<div vocab="https://schema.org/" typeof="Person" resource="#alice">
+		<span property="name">Alice</span>
+		<a property="url" href="/alice">Profile</a>
+		<time property="birthDate" datetime="1990-01-02">2 Jan</time>
+		<div property="worksFor" typeof="Organization">
+			<span property="name">Acme</span>
+		</div>
+	</div>
+</body></html>`
+
+// TestDocument_StructuredData verifies JSON-LD, microdata, RDFa-lite and meta tags
+// are extracted into entities
+func TestDocument_StructuredData(t *testing.T) {
+	data, err := GetStructuredData(structuredPage)
+	if err != nil {
+		t.Fatalf("GetStructuredData() error = %v", err)
+	}
+
+	t.Run("JSON-LD", func(t *testing.T) {
+		if len(data.JSONLD) != 3 {
+			t.Fatalf("JSONLD has %d entities, want 3 (two @graph nodes and the article)", len(data.JSONLD))
+		}
+
+		product := data.First("Product")
+		if product == nil || product.Source != SourceJSONLD {
+			t.Fatalf("First(Product) = %+v, want the JSON-LD product", product)
+		}
+		tests := []struct {
+			name     string
+			got      any
+			expected any
+		}{
+			{"name", product.Text("name"), "Blue Widget"},
+			{"sku", product.Text("sku"), "1234"},
+			{"image", product.Properties["image"], []any{"a.jpg", "b.jpg"}},
+			{"@value", product.Text("description"), "A widget"},
+			{"nested price", product.Entity("offers").Value("price"), 19.99},
+			{"nested entity", product.Text("offers"), ""},
+			{"missing", product.Value("color"), nil},
+		}
+		for _, tt := range tests {
+			if !reflect.DeepEqual(tt.got, tt.expected) {
+				t.Errorf("%s = %#v, want %#v", tt.name, tt.got, tt.expected)
+			}
+		}
+
+		publisher := data.First("WebPage").Entity("publisher")
+		if org := data.ByID(publisher.ID); org == nil || org.Text("name") != "Example Shop" {
+			t.Errorf("ByID(%q) = %+v, want the organization", publisher.ID, org)
+		}
+		if article := data.First("NewsArticle"); article == nil || article.Text("headline") != "Launch" {
+			t.Errorf("First(NewsArticle) = %+v, want the commented out article", article)
+		}
+	})
+
+	t.Run("Microdata", func(t *testing.T) {
+		if len(data.Microdata) != 1 {
+			t.Fatalf("Microdata has %d items, want 1", len(data.Microdata))
+		}
+		product := data.Microdata[0]
+		if !product.Is("Product") || product.ID != "urn:sku:42" {
+			t.Errorf("Item types = %v, id = %q", product.Types, product.ID)
+		}
+
+		expected := map[string]string{
+			"name":     "Red Widget",
+			"image":    "https://shop.example.com/catalog/red.jpg",
+			"url":      "https://shop.example.com/products/red",
+			"brand":    "Acme",
+			"keywords": "Acme",
+		}
+		for name, value := range expected {
+			if got := product.Text(name); got != value {
+				t.Errorf("%s = %q, want %q", name, got, value)
+			}
+		}
+
+		offer := product.Entity("offers")
+		if offer == nil || offer.Text("price") != "9.50" || offer.Text("priceCurrency") != "EUR" ||
+			offer.Text("availability") != "https://schema.org/OutOfStock" {
+			t.Errorf("offers = %+v", offer)
+		}
+		if rating := product.Entity("aggregateRating"); rating == nil || rating.Text("ratingValue") != "4.5" {
+			t.Errorf("itemref aggregateRating = %+v", rating)
+		}
+		if got := len(data.All("Offer")); got != 2 {
+			t.Errorf("All(Offer) found %d offers, want one JSON-LD and one microdata", got)
+		}
+	})
+
+	t.Run("RDFa", func(t *testing.T) {
+		if len(data.RDFa) != 1 {
+			t.Fatalf("RDFa has %d items, want 1", len(data.RDFa))
+		}
+		person := data.First("Person")
+		if person == nil || person.ID != "#alice" || person.Text("name") != "Alice" ||
+			person.Text("url") != "https://shop.example.com/alice" || person.Text("birthDate") != "1990-01-02" {
+			t.Errorf("First(Person) = %+v", person)
+		}
+		if org := person.Entity("worksFor"); org == nil || !org.Is("Organization") || org.Text("name") != "Acme" {
+			t.Errorf("worksFor = %+v", org)
+		}
+	})
+
+	t.Run("Meta tags", func(t *testing.T) {
+		expected := MetaTags{
+			"og:title":       {"Blue Widget"},
+			"og:image":       {"https://shop.example.com/1.jpg", "https://shop.example.com/2.jpg"},
+			"article:author": {"Alice"},
+			"og:site_name":   {"Example Shop"},
+		}
+		if !reflect.DeepEqual(data.OpenGraph, expected) {
+			t.Errorf("OpenGraph = %v, want %v", data.OpenGraph, expected)
+		}
+		if data.Twitter.Get("twitter:card") != "summary_large_image" || data.Twitter.Get("twitter:site") != "@example" {
+			t.Errorf("Twitter = %v", data.Twitter)
+		}
+	})
+}
+
+// TestEntity_Decode verifies entities decode into typed structs
+func TestEntity_Decode(t *testing.T) {
+	data, err := GetStructuredData(structuredPage)
+	if err != nil {
+		t.Fatalf("GetStructuredData() error = %v", err)
+	}
+
+	type offer struct {
+		Price    float64 `json:"price"`
+		Currency string  `json:"priceCurrency"`
+	}
+	type product struct {
+		Type   string   `json:"@type"`
+		Name   string   `json:"name"`
+		Images []string `json:"image"`
+		Offers offer    `json:"offers"`
+	}
+
+	var got product
+	if err := data.First("Product").Decode(&got); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	expected := product{
+		Type:   "Product",
+		Name:   "Blue Widget",
+		Images: []string{"a.jpg", "b.jpg"},
+		Offers: offer{Price: 19.99, Currency: "USD"},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Decode() = %+v, want %+v", got, expected)
+	}
+
+	// Microdata values are strings
+	var microdataOffer struct {
+		Price float64 `json:"price,string"`
+	}
+	if err := data.Microdata[0].Entity("offers").Decode(&microdataOffer); err != nil || microdataOffer.Price != 9.5 {
+		t.Errorf("Decode() of a microdata offer = %+v, %v", microdataOffer, err)
+	}
+}
+
+// TestScraper_ScrapeStructuredData verifies fetched pages resolve links against their URL
+func TestScraper_ScrapeStructuredData(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<div itemscope itemtype="https://schema.org/Article">
+			<h1 itemprop="headline">Hello</h1><a itemprop="url" href="/posts/1">Read</a>
+		</div>`))
+	}))
+	defer server.Close()
+
+	s := New(Options{MaxRetries: 1})
+	data, err := s.ScrapeStructuredData(server.URL + "/blog")
+	if err != nil {
+		t.Fatalf("ScrapeStructuredData() error = %v", err)
+	}
+	article := data.First("Article")
+	if article == nil || article.Text("headline") != "Hello" || article.Text("url") != server.URL+"/posts/1" {
+		t.Errorf("First(Article) = %+v", article)
+	}
+	if data.First("Product") != nil {
+		t.Error("Expected no Product entity")
+	}
+}
@@ -0,0 +1,270 @@
+package scraper
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Unmarshal extracts data from HTML into the struct pointed to by v
+// Fields are mapped with `scrape` struct tags holding a CSS selector followed by
+// optional ';'-separated options:
+//
+//	Name   string    `scrape:"h1.title"`
+//	Price  float64   `scrape:"span.price;default=0"`
+//	Link   string    `scrape:"a.more;attr=href"`
+//	Body   string    `scrape:"div.body;html"`
+//	Date   time.Time `scrape:"time;attr=datetime;format=2006-01-02"` // RFC 3339 if no format
+//	Tags   []string  `scrape:"ul.tags li"`
+//	Seller *Seller   `scrape:"div.seller"`
+//	Items  []Item    `scrape:"li.item"`
+//
+// Nested structs are scoped to the first element matched by their selector and
+// slices of structs to each matched element. An empty selector refers to the
+// current scope. Pointer fields stay nil when nothing matches.
+// The document is parsed once for the whole struct.
+func Unmarshal(htmlText string, v any) error {
+	doc, err := NewDocument(htmlText)
+	if err != nil {
+		return err
+	}
+	return doc.Unmarshal(v)
+}
+
+// unmarshalSelection extracts data from sel into the struct pointed to by v
+func unmarshalSelection(sel *goquery.Selection, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("unmarshal target must be a non-nil pointer to a struct")
+	}
+	return unmarshalStruct(sel, rv.Elem())
+}
+
+// fieldTag holds the parsed contents of a `scrape` struct tag
+type fieldTag struct {
+	selector   string
+	attr       string
+	html       bool
+	format     string
+	defaultVal string
+	hasDefault bool
+}
+
+// parseFieldTag parses `selector;attr=name;html;format=layout;default=value`
+func parseFieldTag(tag string) (fieldTag, error) {
+	parts := strings.Split(tag, ";")
+	ft := fieldTag{selector: strings.TrimSpace(parts[0])}
+
+	for _, option := range parts[1:] {
+		name, value, _ := strings.Cut(strings.TrimSpace(option), "=")
+		switch name {
+		case "attr":
+			ft.attr = value
+		case "html":
+			ft.html = true
+		case "format":
+			ft.format = value
+		case "default":
+			ft.defaultVal = value
+			ft.hasDefault = true
+		case "":
+		default:
+			return ft, fmt.Errorf("unknown tag option %q", name)
+		}
+	}
+
+	// Attribute selectors extract the attribute, like GetText does
+	if ft.attr == "" && !ft.html {
+		ft.attr = GetAttrName(ft.selector)
+	}
+
+	return ft, nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func unmarshalStruct(scope *goquery.Selection, rv reflect.Value) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		tag, ok := field.Tag.Lookup("scrape")
+		if !ok || tag == "-" || !field.IsExported() {
+			continue
+		}
+
+		ft, err := parseFieldTag(tag)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+
+		if err := unmarshalField(scope, rv.Field(i), ft); err != nil {
+			return fmt.Errorf("field %s (selector %q): %w", field.Name, ft.selector, err)
+		}
+	}
+	return nil
+}
+
+// findAll returns every element matching selector within scope, honouring "||"
+// An empty selector returns the scope itself
+func findAll(scope *goquery.Selection, selector string) []*goquery.Selection {
+	if selector == "" {
+		return []*goquery.Selection{scope}
+	}
+
+	var matches []*goquery.Selection
+	for _, sel := range getSelectors(selector) {
+		scope.Find(strings.TrimSpace(sel)).Each(func(_ int, s *goquery.Selection) {
+			matches = append(matches, s)
+		})
+	}
+	return matches
+}
+
+// selectionText returns the value of an element according to the tag options
+func selectionText(s *goquery.Selection, ft fieldTag) string {
+	switch {
+	case ft.html:
+		html, _ := goquery.OuterHtml(s)
+		return html
+	case ft.attr != "":
+		value, _ := s.Attr(ft.attr)
+		return value
+	default:
+		return strings.TrimSpace(s.Text())
+	}
+}
+
+func unmarshalField(scope *goquery.Selection, fv reflect.Value, ft fieldTag) error {
+	matches := findAll(scope, ft.selector)
+
+	switch {
+	case fv.Kind() == reflect.Pointer:
+		if len(matches) == 0 && !ft.hasDefault {
+			return nil
+		}
+		elem := reflect.New(fv.Type().Elem())
+		if err := unmarshalField(scope, elem.Elem(), ft); err != nil {
+			return err
+		}
+		fv.Set(elem)
+		return nil
+
+	case fv.Kind() == reflect.Struct && fv.Type() != timeType:
+		if len(matches) == 0 {
+			return nil
+		}
+		return unmarshalStruct(matches[0], fv)
+
+	case fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() != reflect.Uint8:
+		elemType := fv.Type().Elem()
+		for elemType.Kind() == reflect.Pointer {
+			elemType = elemType.Elem()
+		}
+		scalar := elemType.Kind() != reflect.Struct || elemType == timeType
+
+		slice := reflect.MakeSlice(fv.Type(), 0, len(matches))
+		for i, match := range matches {
+			// Empty values are skipped, like GetText does
+			if scalar && !ft.hasDefault && selectionText(match, ft) == "" {
+				continue
+			}
+			elem := reflect.New(fv.Type().Elem()).Elem()
+			if err := unmarshalElement(match, elem, ft); err != nil {
+				return fmt.Errorf("item %d: %w", i, err)
+			}
+			slice = reflect.Append(slice, elem)
+		}
+		fv.Set(slice)
+		return nil
+	}
+
+	text := ""
+	if len(matches) > 0 {
+		text = selectionText(matches[0], ft)
+	}
+	if text == "" {
+		if !ft.hasDefault {
+			return nil
+		}
+		text = ft.defaultVal
+	}
+	return setValue(fv, text, ft)
+}
+
+// unmarshalElement fills a single slice element from a matched element
+func unmarshalElement(match *goquery.Selection, ev reflect.Value, ft fieldTag) error {
+	if ev.Kind() == reflect.Pointer {
+		elem := reflect.New(ev.Type().Elem())
+		if err := unmarshalElement(match, elem.Elem(), ft); err != nil {
+			return err
+		}
+		ev.Set(elem)
+		return nil
+	}
+
+	if ev.Kind() == reflect.Struct && ev.Type() != timeType {
+		return unmarshalStruct(match, ev)
+	}
+
+	text := selectionText(match, ft)
+	if text == "" && ft.hasDefault {
+		text = ft.defaultVal
+	}
+	return setValue(ev, text, ft)
+}
+
+// setValue converts text into the kind of fv
+func setValue(fv reflect.Value, text string, ft fieldTag) error {
+	if fv.Type() == timeType {
+		format := ft.format
+		if format == "" {
+			format = time.RFC3339
+		}
+		parsed, err := parseTime(text, format)
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(*parsed))
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(text)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		val, err := parseFloat(text)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(int64(val))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		val, err := parseFloat(text)
+		if err != nil {
+			return err
+		}
+		if val < 0 {
+			return fmt.Errorf("cannot store negative value '%s' in unsigned field", text)
+		}
+		fv.SetUint(uint64(val))
+	case reflect.Float32, reflect.Float64:
+		val, err := parseFloat(text)
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(val)
+	case reflect.Bool:
+		val, err := strconv.ParseBool(text)
+		if err != nil {
+			return fmt.Errorf("failed to convert '%s' to bool: %w", text, err)
+		}
+		fv.SetBool(val)
+	default:
+		return fmt.Errorf("unsupported field type %s", fv.Type())
+	}
+	return nil
+}
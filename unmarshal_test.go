@@ -0,0 +1,160 @@
+package scraper
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const productHTML = `
+<html>
+	<body>
+		<div class="product">
+			<h1 class="name">  Trail Shoe  </h1>
+			<span class="price">$1,299.50</span>
+			<span class="stock" data-qty="42">In stock</span>
+			<a class="more" href="/shoes/trail">Details</a>
+			<time datetime="2024-03-01">March 1</time>
+			<div class="desc"><p>Light <b>and</b> fast</p></div>
+			<ul class="tags"><li>running</li><li> </li><li>outdoor</li></ul>
+			<div class="seller"><span class="name">Acme</span><span class="rating">4.5</span></div>
+			<ul class="variants">
+				<li class="variant"><span class="size">9</span><span class="color">red</span></li>
+				<li class="variant"><span class="size">10</span><span class="color">blue</span></li>
+			</ul>
+		</div>
+	</body>
+</html>`
+
+type testSeller struct {
+	Name   string  `scrape:"span.name"`
+	Rating float64 `scrape:"span.rating"`
+}
+
+type testVariant struct {
+	Size  int    `scrape:"span.size"`
+	Color string `scrape:"span.color"`
+}
+
+type testProduct struct {
+	Name      string        `scrape:"h1.name"`
+	Price     float64       `scrape:"span.price"`
+	Stock     int           `scrape:"span.stock[data-qty]"`
+	Link      string        `scrape:"a.more;attr=href"`
+	Released  time.Time     `scrape:"time;attr=datetime;format=2006-01-02"`
+	Desc      string        `scrape:"div.desc p;html"`
+	Tags      []string      `scrape:"ul.tags li"`
+	Seller    testSeller    `scrape:"div.seller"`
+	Variants  []testVariant `scrape:"li.variant"`
+	Discount  *float64      `scrape:"span.discount"`
+	Rating    *float64      `scrape:"div.seller span.rating"`
+	Currency  string        `scrape:"span.currency;default=USD"`
+	Ignored   string        `scrape:"-"`
+	untagged  string
+	Untagged2 string
+}
+
+// TestUnmarshal verifies struct tag extraction into typed fields
+func TestUnmarshal(t *testing.T) {
+	var p testProduct
+	if err := Unmarshal(productHTML, &p); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if p.Name != "Trail Shoe" {
+		t.Errorf("Name = %q", p.Name)
+	}
+	if p.Price != 1299.50 {
+		t.Errorf("Price = %v", p.Price)
+	}
+	if p.Stock != 42 {
+		t.Errorf("Stock = %d", p.Stock)
+	}
+	if p.Link != "/shoes/trail" {
+		t.Errorf("Link = %q", p.Link)
+	}
+	if !p.Released.Equal(time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Released = %v", p.Released)
+	}
+	if p.Desc != "<p>Light <b>and</b> fast</p>" {
+		t.Errorf("Desc = %q", p.Desc)
+	}
+	if strings.Join(p.Tags, ",") != "running,outdoor" {
+		t.Errorf("Tags = %v", p.Tags)
+	}
+	if p.Seller.Name != "Acme" || p.Seller.Rating != 4.5 {
+		t.Errorf("Seller = %+v", p.Seller)
+	}
+	if len(p.Variants) != 2 || p.Variants[0] != (testVariant{9, "red"}) || p.Variants[1] != (testVariant{10, "blue"}) {
+		t.Errorf("Variants = %+v", p.Variants)
+	}
+	if p.Discount != nil {
+		t.Errorf("Discount = %v, want nil", *p.Discount)
+	}
+	if p.Rating == nil || *p.Rating != 4.5 {
+		t.Errorf("Rating = %v, want 4.5", p.Rating)
+	}
+	if p.Currency != "USD" {
+		t.Errorf("Currency = %q, want default 'USD'", p.Currency)
+	}
+}
+
+// TestUnmarshal_ScopeAndMultipleSelectors verifies empty selectors and "||" alternatives
+func TestUnmarshal_ScopeAndMultipleSelectors(t *testing.T) {
+	type link struct {
+		Href string `scrape:";attr=href"`
+		Text string `scrape:""`
+	}
+	var page struct {
+		Links []link `scrape:"a"`
+		Title string `scrape:"h2.missing||h1"`
+	}
+
+	html := `<h1>Title</h1><a href="/a">A</a><a href="/b">B</a>`
+	if err := Unmarshal(html, &page); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if page.Title != "Title" {
+		t.Errorf("Title = %q", page.Title)
+	}
+	if len(page.Links) != 2 || page.Links[1] != (link{"/b", "B"}) {
+		t.Errorf("Links = %+v", page.Links)
+	}
+}
+
+// TestUnmarshal_Errors verifies invalid targets and conversion failures
+func TestUnmarshal_Errors(t *testing.T) {
+	var notStruct int
+	if err := Unmarshal("<p>1</p>", &notStruct); err == nil {
+		t.Error("Expected error for non-struct target")
+	}
+
+	var notPointer struct{}
+	if err := Unmarshal("<p>1</p>", notPointer); err == nil {
+		t.Error("Expected error for non-pointer target")
+	}
+
+	var bad struct {
+		Count int `scrape:"p"`
+	}
+	err := Unmarshal("<p>many</p>", &bad)
+	if err == nil || !strings.Contains(err.Error(), "Count") {
+		t.Errorf("Expected conversion error naming the field, got: %v", err)
+	}
+
+	var badTag struct {
+		Count int `scrape:"p;bogus"`
+	}
+	if err := Unmarshal("<p>1</p>", &badTag); err == nil {
+		t.Error("Expected error for unknown tag option")
+	}
+}
+
+// BenchmarkUnmarshal benchmarks struct extraction
+func BenchmarkUnmarshal(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		var p testProduct
+		_ = Unmarshal(productHTML, &p)
+	}
+}
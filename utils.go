@@ -1,7 +1,9 @@
 package scraper
 
 import (
+	"errors"
 	"fmt"
+	"net/url"
 	"regexp"
 	"strconv"
 	"strings"
@@ -12,22 +14,68 @@ import (
 
 type ExtractionFunc func(i int, s *goquery.Selection)
 
+// GetBaseURL returns the scheme, userinfo and host of fullURL
+// Returns fullURL unchanged if it is not an absolute URL
 func GetBaseURL(fullURL string) string {
-	// Use regex to extract the base URL (scheme + domain)
-	pattern := `^(https?://[^/]+)`
-	re := regexp.MustCompile(pattern)
-	matches := re.FindStringSubmatch(fullURL)
-	if len(matches) < 2 {
-		return fullURL // Return as is if no match found
+	u, err := url.Parse(strings.TrimSpace(fullURL))
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return fullURL
 	}
-	return matches[1]
+	return (&url.URL{Scheme: u.Scheme, User: u.User, Host: u.Host}).String()
 }
 
+// GetFullURL resolves relativePath against baseURL following RFC 3986
+// Handles absolute paths, "../" segments, query-only and protocol-relative references
+// Returns relativePath unchanged if either URL cannot be parsed
 func GetFullURL(baseURL, relativePath string) string {
-	if strings.HasPrefix(relativePath, "/") {
-		return GetBaseURL(baseURL) + relativePath
+	ref, err := url.Parse(strings.TrimSpace(relativePath))
+	if err != nil {
+		return relativePath
+	}
+	base, err := url.Parse(strings.TrimSpace(baseURL))
+	if err != nil {
+		return relativePath
+	}
+	return base.ResolveReference(ref).String()
+}
+
+// NormalizeURL returns a canonical form of rawURL suitable for deduplication
+// The scheme and host are lowercased, default ports, fragments and dot segments
+// are removed, an empty path becomes "/" and query parameters are sorted
+func NormalizeURL(rawURL string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return "", err
 	}
-	return relativePath // Already a full URL
+	if u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("cannot normalize relative URL %q", rawURL)
+	}
+
+	// Resolving an empty reference removes dot segments from the path
+	u = u.ResolveReference(&url.URL{})
+	u.Fragment, u.RawFragment = "", ""
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	if port := u.Port(); (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		u.Host = u.Hostname()
+		if strings.Contains(u.Host, ":") {
+			// Keep IPv6 literals bracketed
+			u.Host = "[" + u.Host + "]"
+		}
+	}
+	if u.Path == "" {
+		u.Path = "/"
+		u.RawPath = ""
+	}
+	u.ForceQuery = false
+	if u.RawQuery != "" {
+		query, err := url.ParseQuery(u.RawQuery)
+		if err == nil {
+			u.RawQuery = query.Encode()
+		}
+	}
+
+	return u.String(), nil
 }
 
 // GetAttrName extracts the attribute name from a CSS selector with attribute selector
@@ -74,18 +122,12 @@ func getTexts(results *[]string, selector string, first bool) ExtractionFunc {
 	}
 }
 
-func getResults(htmlText, selector string, fn ExtractionFunc) error {
-	doc, err := goquery.NewDocumentFromReader(strings.NewReader(htmlText))
-	if err != nil {
-		return err
-	}
-
+// eachResult runs fn on every element of scope matching selector, honouring "||"
+func eachResult(scope *goquery.Selection, selector string, fn ExtractionFunc) {
 	for _, sel := range getSelectors(selector) {
 		sel := strings.TrimSpace(sel)
-		doc.Find(sel).Each(fn)
+		scope.Find(sel).Each(fn)
 	}
-
-	return nil
 }
 
 // GetOuterHTML extracts the outer HTML of elements matching the given CSS selector from HTML text
@@ -94,13 +136,12 @@ func GetOuterHTML(htmlText, selector string) ([]string, error) {
 	if selector == "" {
 		return []string{}, nil
 	}
-	var results []string
-	err := getResults(htmlText, selector, gethtmls(&results))
+	doc, err := NewDocument(htmlText)
 	if err != nil {
 		return nil, err
 	}
 
-	return results, nil
+	return doc.GetOuterHTML(selector), nil
 }
 
 // GetText extracts the text content of elements matching the given CSS selector from HTML text
@@ -109,13 +150,12 @@ func GetText(htmlText, selector string) ([]string, error) {
 	if selector == "" {
 		return []string{}, nil
 	}
-	var results []string
-	err := getResults(htmlText, selector, getTexts(&results, selector, false))
+	doc, err := NewDocument(htmlText)
 	if err != nil {
 		return nil, err
 	}
 
-	return results, nil
+	return doc.GetText(selector), nil
 }
 
 // GetTextSingle extracts the text content of the first element matching the given CSS selector
@@ -124,21 +164,16 @@ func GetTextSingle(htmlText, selector string) (string, error) {
 	if selector == "" {
 		return "", nil
 	}
-	var results []string
-	err := getResults(htmlText, selector, getTexts(&results, selector, true))
+	doc, err := NewDocument(htmlText)
 	if err != nil {
 		return "", err
 	}
 
-	if len(results) == 0 {
-		return "", nil
-	}
-
-	return results[0], nil
+	return doc.GetTextSingle(selector), nil
 }
 
 // GetInt extracts text from the first element matching the selector and converts it to int
-// Returns 0 if no match found or conversion fails
+// Returns 0 if no match found, and an *ExtractionError if the text is not a number
 func GetInt(htmlText, selector string) (int, error) {
 	floatVal, err := GetFloat(htmlText, selector)
 	if err != nil {
@@ -149,7 +184,7 @@ func GetInt(htmlText, selector string) (int, error) {
 }
 
 // GetFloat extracts text from the first element matching the selector and converts it to float64
-// Returns 0.0 if no match found or conversion fails
+// Returns 0.0 if no match found, and an *ExtractionError if the text is not a number
 func GetFloat(htmlText, selector string) (float64, error) {
 	text, err := GetTextSingle(htmlText, selector)
 	if err != nil {
@@ -160,6 +195,15 @@ func GetFloat(htmlText, selector string) (float64, error) {
 		return 0.0, nil
 	}
 
+	value, err := parseFloat(text)
+	if err != nil {
+		return 0.0, &ExtractionError{Selector: selector, Err: err}
+	}
+	return value, nil
+}
+
+// parseFloat converts scraped text to float64, ignoring currency symbols, commas and spaces
+func parseFloat(text string) (float64, error) {
 	// Clean the text - remove commas, currency symbols, and spaces using regex
 	cleanPattern := regexp.MustCompile(`[^0-9-.]+`)
 	cleanText := cleanPattern.ReplaceAllString(text, "")
@@ -174,6 +218,7 @@ func GetFloat(htmlText, selector string) (float64, error) {
 
 // GetTime extracts text from the first element matching the selector and returns it as a string
 // This function can be extended to parse dates into specific formats if needed
+// Returns an *ExtractionError if no match is found or the date cannot be parsed
 func GetTime(htmlText, selector, format string) (*time.Time, error) {
 	text, err := GetTextSingle(htmlText, selector)
 	if err != nil {
@@ -181,9 +226,19 @@ func GetTime(htmlText, selector, format string) (*time.Time, error) {
 	}
 
 	if text == "" {
-		return nil, fmt.Errorf("failed to get date text")
+		return nil, &ExtractionError{Selector: selector, Err: errors.New("failed to get date text")}
 	}
 
+	t, err := parseTime(text, format)
+	if err != nil {
+		return nil, &ExtractionError{Selector: selector, Err: err}
+	}
+	return t, nil
+}
+
+// parseTime parses scraped text with the given layout
+// The special format "ago" handles relative times like "2 days ago"
+func parseTime(text, format string) (*time.Time, error) {
 	if format == "" {
 		return nil, fmt.Errorf("date format is required")
 	}